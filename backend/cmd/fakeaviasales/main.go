@@ -0,0 +1,50 @@
+// Command fakeaviasales runs the local flight search stand-in. Point the backend at it with
+//
+//	INIT_SEARCH_URL=http://localhost:8090/v1/flight_search
+//	RESULT_SEARCH_URL=http://localhost:8090/v1/flight_search_results?uuid=%s
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"stopover.backend/pkg/aviasales/fake"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	token := flag.String("token", "", "verify request signatures with this token")
+	fixture := flag.String("fixture", "", "offers fixture file; the embedded one is used when empty")
+	polls := flag.Int("polls", 3, "result polls before a search completes")
+	chunks := flag.Int("chunks", 2, "array elements per poll response")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	errorRate := flag.Float64("error-rate", 0, "probability of a 500 on any request")
+	failPolls := flag.Int("fail-polls", 0, "fail the first n result polls of every search")
+	gz := flag.Bool("gzip", true, "gzip responses for clients that accept it")
+	flag.Parse()
+
+	opts := fake.Options{
+		Token:         *token,
+		Polls:         *polls,
+		ChunksPerPoll: *chunks,
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		FailPolls:     *failPolls,
+		Gzip:          *gz,
+	}
+	if *fixture != "" {
+		f, err := os.Open(*fixture)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if opts.Fixture, err = fake.LoadFixture(f); err != nil {
+			log.Fatalf("load fixture: %v", err)
+		}
+		f.Close()
+	}
+
+	log.Printf("fake aviasales listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, fake.New(opts)))
+}
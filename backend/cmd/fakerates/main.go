@@ -0,0 +1,31 @@
+// Command fakerates serves exchange rates for local development. Point the backend at it with
+//
+//	CURRENCY_RATES_SOURCE=http
+//	CURRENCY_RATES_URL=http://localhost:8091/latest
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"stopover.backend/internal/currency"
+)
+
+func main() {
+	addr := flag.String("addr", ":8091", "listen address")
+	file := flag.String("file", "", "rate document to serve; the embedded rates are served when empty")
+	flag.Parse()
+
+	var doc []byte
+	if *file != "" {
+		var err error
+		if doc, err = os.ReadFile(*file); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("fake rates listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, currency.NewStubHandler(doc)))
+}
@@ -0,0 +1,26 @@
+// Command fakesmtp accepts and logs mail for local development. Point the backend at it with
+//
+//	SMTP_HOST=localhost
+//	SMTP_PORT=2525
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"stopover.backend/internal/alerts"
+)
+
+func main() {
+	addr := flag.String("addr", ":2525", "listen address")
+	flag.Parse()
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("fake smtp listening on %s", *addr)
+	log.Fatal(alerts.NewStubSMTPServer().Serve(l))
+}
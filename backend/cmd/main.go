@@ -1,6 +1,9 @@
 package main
 
 import (
+	// embed the IANA zone database so flight times parse without system tzdata
+	_ "time/tzdata"
+
 	"stopover.backend/config"
 	"stopover.backend/internal/api"
 )
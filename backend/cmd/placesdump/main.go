@@ -0,0 +1,70 @@
+// Command placesdump refreshes the airport reference data from the Travelpayouts data dumps and
+// writes it in the format of internal/places/airports.csv. Traffic figures are not in the dumps
+// and are carried over from the base dataset. Load the result with
+//
+//	PLACES_FILE=/path/to/airports.csv
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"stopover.backend/internal/places"
+)
+
+func main() {
+	out := flag.String("out", "airports.csv", "file to write")
+	base := flag.String("base", "", "dataset to carry traffic and aliases over from; the embedded one is used when empty")
+	url := flag.String("url", places.TravelpayoutsDumpURL, "dump url pattern; %s is airports, cities or countries")
+	flag.Parse()
+
+	known := places.DefaultAirports()
+	if *base != "" {
+		var err error
+		if known, err = places.LoadFile(*base); err != nil {
+			log.Fatalf("load base: %v", err)
+		}
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	dumps := make(map[string]io.Reader)
+	for _, name := range []string{"airports", "cities", "countries"} {
+		body, err := fetch(client, fmt.Sprintf(*url, name))
+		if err != nil {
+			log.Fatalf("fetch %s: %v", name, err)
+		}
+		dumps[name] = bytes.NewReader(body)
+	}
+
+	airports, err := places.ReadTravelpayouts(dumps["airports"], dumps["cities"], dumps["countries"], known)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := places.WriteCSV(&buf, airports); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d airports to %s", len(airports), *out)
+}
+
+func fetch(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
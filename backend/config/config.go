@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -20,15 +21,64 @@ type Config struct {
 	SecretKey          string          `mapstructure:"SECRET_KEY"`
 	RedisRoleAccessKey string          `mapstructure:"ROLE_ACCESS_KEY"`
 	RedisHostPort      string          `mapstructure:"REDIS_HOST_PORT"`
+	SearchSessionTTL   time.Duration   `mapstructure:"SEARCH_SESSION_TTL"`
+	SearchCacheTTL     time.Duration   `mapstructure:"SEARCH_CACHE_TTL"`
+	PlacesFile         string          `mapstructure:"PLACES_FILE"`
+	AirlineLogoURL     string          `mapstructure:"AIRLINE_LOGO_URL"`
+	CurrencyConfig     CurrencyConfig  `mapstructure:",squash"`
+	AlertConfig        AlertConfig     `mapstructure:",squash"`
 	AviaSalesConfig    AviaSalesConfig `mapstructure:",squash"`
 }
 
+// exchange rate source: "static" uses the rates embedded in the binary, "file" reads RatesFile
+// and "http" fetches RatesURL; fetched rates are cached for RatesTTL
+type CurrencyConfig struct {
+	RatesSource string        `mapstructure:"CURRENCY_RATES_SOURCE"`
+	RatesFile   string        `mapstructure:"CURRENCY_RATES_FILE"`
+	RatesURL    string        `mapstructure:"CURRENCY_RATES_URL"`
+	RatesTTL    time.Duration `mapstructure:"CURRENCY_RATES_TTL"`
+}
+
+// price alert monitor and notification delivery; zero durations and limits fall back to the
+// monitor defaults and email alerts are offered only when SMTPHost is set
+type AlertConfig struct {
+	CheckInterval     time.Duration `mapstructure:"ALERT_CHECK_INTERVAL"`
+	PollInterval      time.Duration `mapstructure:"ALERT_POLL_INTERVAL"`
+	SearchesPerMinute int           `mapstructure:"ALERT_SEARCHES_PER_MINUTE"`
+	SMTPHost          string        `mapstructure:"SMTP_HOST"`
+	SMTPPort          string        `mapstructure:"SMTP_PORT"`
+	SMTPUser          string        `mapstructure:"SMTP_USER"`
+	SMTPPassword      string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom          string        `mapstructure:"SMTP_FROM"`
+}
+
+// DefaultBookingURL resolves booking links with the Travelpayouts click API
+const DefaultBookingURL = "https://api.travelpayouts.com/v1/flight_searches/%s/clicks/%s.json"
+
 type AviaSalesConfig struct {
 	InitSearchURL   string `mapstructure:"INIT_SEARCH_URL"`
 	ResultSearchURL string `mapstructure:"RESULT_SEARCH_URL"`
+	// format with the search id and term url, e.g. .../v1/flight_searches/%s/clicks/%s.json;
+	// DefaultBookingURL when empty
+	BookingURL      string `mapstructure:"BOOKING_URL"`
 	AviaSalesToken  string `mapstructure:"AVIASALES_TOKEN"`
 	AviaSalesMarker string `mapstructure:"AVIASALES_MARKER"`
 	AviaSalesHost   string `mapstructure:"AVIASALES_HOST"`
+
+	// supplier transport; zero values fall back to the client defaults
+	MaxRetries       int           `mapstructure:"AVIASALES_MAX_RETRIES"`
+	RetryBaseDelay   time.Duration `mapstructure:"AVIASALES_RETRY_BASE_DELAY"`
+	RetryMaxDelay    time.Duration `mapstructure:"AVIASALES_RETRY_MAX_DELAY"`
+	AttemptTimeout   time.Duration `mapstructure:"AVIASALES_ATTEMPT_TIMEOUT"`
+	BreakerThreshold int           `mapstructure:"AVIASALES_BREAKER_THRESHOLD"`
+	BreakerCooldown  time.Duration `mapstructure:"AVIASALES_BREAKER_COOLDOWN"`
+
+	// limit on a decompressed results response in bytes
+	MaxBodyBytes int64 `mapstructure:"AVIASALES_MAX_BODY_BYTES"`
+
+	// record or replay supplier traffic in CassetteDir; empty mode talks to the supplier directly
+	CassetteMode string `mapstructure:"AVIASALES_CASSETTE_MODE"`
+	CassetteDir  string `mapstructure:"AVIASALES_CASSETTE_DIR"`
 }
 
 var AppConfig Config
@@ -85,4 +135,34 @@ func LoadConfig() {
 	if err != nil {
 		panic(fmt.Errorf("fatal error when re-unmarshaling config: %s", err))
 	}
+
+	if AppConfig.AviaSalesConfig.BookingURL == "" {
+		AppConfig.AviaSalesConfig.BookingURL = DefaultBookingURL
+	}
+	if err := CheckBookingURL(AppConfig.AviaSalesConfig.BookingURL); err != nil {
+		panic(fmt.Errorf("fatal error in config: %s", err))
+	}
+}
+
+// CheckBookingURL requires a booking url format with exactly two %s verbs, for the search id and
+// the term url, and no other verbs
+func CheckBookingURL(format string) error {
+	verbs := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		switch {
+		case i < len(format) && format[i] == '%':
+		case i < len(format) && format[i] == 's':
+			verbs++
+		default:
+			return fmt.Errorf("BOOKING_URL %q: only %%s verbs are allowed", format)
+		}
+	}
+	if verbs != 2 {
+		return fmt.Errorf("BOOKING_URL %q: need exactly two %%s verbs, for the search id and term, got %d", format, verbs)
+	}
+	return nil
 }
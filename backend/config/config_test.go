@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestCheckBookingURL(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: DefaultBookingURL},
+		{format: "http://localhost:8090/v1/flight_searches/%s/clicks/%s.json?src=100%%"},
+		{format: "https://api.travelpayouts.com/v1/flight_searches/clicks.json", wantErr: true},
+		{format: "https://api.travelpayouts.com/v1/flight_searches/%s/clicks.json", wantErr: true},
+		{format: "https://example.com/%s/%s/%s.json", wantErr: true},
+		{format: "https://example.com/%s/clicks/%d.json", wantErr: true},
+		{format: "https://example.com/%s/clicks/%v", wantErr: true},
+		{format: "https://example.com/%s/clicks/%s%", wantErr: true},
+		{format: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckBookingURL(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("CheckBookingURL(%q) = %v, wantErr %t", tt.format, err, tt.wantErr)
+		}
+	}
+}
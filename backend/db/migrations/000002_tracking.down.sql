@@ -0,0 +1,2 @@
+DROP TABLE if exists tbl_mst_click_out;
+DROP TABLE if exists tbl_mst_search_session;
@@ -0,0 +1,57 @@
+-- tbl_mst_search_session definition
+-- one row per user search that returned results
+-- Drop table
+-- DROP TABLE tbl_mst_search_session;
+CREATE TABLE if not exists tbl_mst_search_session (
+  session_id serial8 NOT NULL,
+  result_id varchar(64) NOT NULL,
+  search_id varchar(64) NULL,
+  origin varchar(3) NOT NULL,
+  destination varchar(3) NOT NULL,
+  departure_date date NOT NULL,
+  return_date date NULL,
+  segment_count int4 NOT NULL,
+  trip_class varchar(1) NULL,
+  adults int4 NOT NULL,
+  children int4 DEFAULT 0 NOT NULL,
+  infants int4 DEFAULT 0 NOT NULL,
+  itinerary_count int4 NOT NULL,
+  min_price_minor int8 NULL,
+  currency varchar(3) NULL,
+  user_id int4 NULL,
+  user_ip varchar(45) NULL,
+  created_at timestamp DEFAULT now() NOT NULL,
+  CONSTRAINT tbl_mst_search_session_pkey PRIMARY KEY (session_id),
+  CONSTRAINT uk_search_session_result_id UNIQUE (result_id),
+  CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES tbl_mst_user (user_id)
+);
+
+CREATE INDEX if not exists idx_search_session_search_id ON tbl_mst_search_session (search_id);
+CREATE INDEX if not exists idx_search_session_created_at ON tbl_mst_search_session (created_at);
+
+-- tbl_mst_click_out definition
+-- one row per redirect of a user to a booking agency
+-- Drop table
+-- DROP TABLE tbl_mst_click_out;
+CREATE TABLE if not exists tbl_mst_click_out (
+  click_out_id serial8 NOT NULL,
+  search_id varchar(64) NOT NULL,
+  result_id varchar(64) NULL,
+  term varchar(64) NOT NULL,
+  gate varchar(20) NOT NULL,
+  agency_click_id varchar(64) NULL,
+  origin varchar(3) NULL,
+  destination varchar(3) NULL,
+  price_minor int8 NULL,
+  currency varchar(3) NULL,
+  user_id int4 NULL,
+  user_ip varchar(45) NULL,
+  user_agent text NULL,
+  referer text NULL,
+  created_at timestamp DEFAULT now() NOT NULL,
+  CONSTRAINT tbl_mst_click_out_pkey PRIMARY KEY (click_out_id),
+  CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES tbl_mst_user (user_id)
+);
+
+CREATE INDEX if not exists idx_click_out_created_at ON tbl_mst_click_out (created_at);
+CREATE INDEX if not exists idx_click_out_search_id ON tbl_mst_click_out (search_id);
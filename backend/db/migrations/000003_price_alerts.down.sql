@@ -0,0 +1,3 @@
+DROP TABLE if exists tbl_mst_worker_lease;
+DROP TABLE if exists tbl_mst_price_history;
+DROP TABLE if exists tbl_mst_price_alert;
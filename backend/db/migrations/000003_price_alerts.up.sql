@@ -0,0 +1,64 @@
+-- tbl_mst_price_alert definition
+-- one row per user watch on the price of a route over a range of departure dates
+-- Drop table
+-- DROP TABLE tbl_mst_price_alert;
+CREATE TABLE if not exists tbl_mst_price_alert (
+  alert_id serial8 NOT NULL,
+  user_id int4 NOT NULL,
+  origin varchar(3) NOT NULL,
+  destination varchar(3) NOT NULL,
+  depart_from date NOT NULL,
+  depart_to date NOT NULL,
+  stay_days int4 NULL,
+  adults int4 DEFAULT 1 NOT NULL,
+  trip_class varchar(1) DEFAULT 'Y' NOT NULL,
+  currency varchar(3) NOT NULL,
+  threshold_minor int8 NOT NULL,
+  channel varchar(20) NOT NULL,
+  target text NULL,
+  user_ip varchar(45) NULL,
+  active bool DEFAULT true NOT NULL,
+  last_price_minor int8 NULL,
+  last_checked_at timestamp NULL,
+  next_check_at timestamp DEFAULT now() NOT NULL,
+  notified_price_minor int8 NULL,
+  notified_at timestamp NULL,
+  created_at timestamp DEFAULT now() NOT NULL,
+  updated_at timestamp DEFAULT now() NOT NULL,
+  CONSTRAINT tbl_mst_price_alert_pkey PRIMARY KEY (alert_id),
+  CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES tbl_mst_user (user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX if not exists idx_price_alert_user_id ON tbl_mst_price_alert (user_id);
+CREATE INDEX if not exists idx_price_alert_due ON tbl_mst_price_alert (next_check_at) WHERE active;
+
+-- tbl_mst_price_history definition
+-- the cheapest fare found by each check of an alert
+-- Drop table
+-- DROP TABLE tbl_mst_price_history;
+CREATE TABLE if not exists tbl_mst_price_history (
+  history_id serial8 NOT NULL,
+  alert_id int8 NOT NULL,
+  departure_date date NULL,
+  return_date date NULL,
+  price_minor int8 NULL,
+  currency varchar(3) NOT NULL,
+  search_id varchar(64) NULL,
+  error text NULL,
+  checked_at timestamp DEFAULT now() NOT NULL,
+  CONSTRAINT tbl_mst_price_history_pkey PRIMARY KEY (history_id),
+  CONSTRAINT fk_alert_id FOREIGN KEY (alert_id) REFERENCES tbl_mst_price_alert (alert_id) ON DELETE CASCADE
+);
+
+CREATE INDEX if not exists idx_price_history_alert_id ON tbl_mst_price_history (alert_id, checked_at);
+
+-- tbl_mst_worker_lease definition
+-- named leases that let one of several backend instances run a background job at a time
+-- Drop table
+-- DROP TABLE tbl_mst_worker_lease;
+CREATE TABLE if not exists tbl_mst_worker_lease (
+  name varchar(64) NOT NULL,
+  holder varchar(128) NOT NULL,
+  expires_at timestamp NOT NULL,
+  CONSTRAINT tbl_mst_worker_lease_pkey PRIMARY KEY (name)
+);
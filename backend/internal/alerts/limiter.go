@@ -0,0 +1,49 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces calls evenly so that at most perMinute of them start in any minute. The supplier
+// rate limits searches per marker, so background checks draw on a budget well below the one
+// interactive searches need.
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func NewLimiter(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		perMinute = DefaultSearchesPerMinute
+	}
+	return &Limiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// Wait blocks until the caller may start its call or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,40 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesCalls(t *testing.T) {
+	l := NewLimiter(6000) // one call every 10ms
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("4 calls took %s, want at least 3 intervals", elapsed)
+	}
+}
+
+func TestLimiterWaitStopsWithContext(t *testing.T) {
+	l := NewLimiter(1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first call waited: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waited %s after the context ended", elapsed)
+	}
+}
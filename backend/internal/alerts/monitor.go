@@ -0,0 +1,298 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/repository"
+	"stopover.backend/internal/search"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/money"
+)
+
+const (
+	DefaultCheckInterval     = 6 * time.Hour
+	DefaultPollInterval      = time.Minute
+	DefaultSearchesPerMinute = 10
+
+	// alerts claimed at a time; a claimed alert is not checked by anyone else until its next check
+	claimBatchSize = 10
+	// searchTimeout bounds one date search including its result polls
+	searchTimeout = 2 * time.Minute
+	// minLeaseTTL keeps the lease alive across the longest search between two renewals
+	minLeaseTTL = 5 * time.Minute
+	leaseName   = "price-alert-monitor"
+	// the supplier wants the end user's address; alerts created before it was recorded use this one
+	fallbackUserIP = "127.0.0.1"
+)
+
+// errLeaseLost stops a round of checks when another instance has taken over the monitor lease
+var errLeaseLost = errors.New("alerts: monitor lease lost")
+
+type MonitorConfig struct {
+	// how often each alert is searched again
+	CheckInterval time.Duration
+	// how often the monitor looks for due alerts and renews its lease
+	PollInterval time.Duration
+	// upper bound on background searches across all instances
+	SearchesPerMinute int
+}
+
+// Monitor searches every departure date of due alerts again, records the cheapest fare found and
+// notifies the user when it is at or below the alert threshold and below the last fare they were
+// told about. Every instance runs a monitor but only the one holding the database lease checks
+// alerts, so SearchesPerMinute is the budget of the whole deployment rather than of each instance.
+type Monitor struct {
+	repo     repository.AlertRepository
+	searcher provider.FlightProvider
+	rates    *currency.Service
+	notifier Notifier
+	limiter  *Limiter
+	holder   string
+
+	checkInterval time.Duration
+	pollInterval  time.Duration
+	leaseTTL      time.Duration
+	leader        bool
+}
+
+func NewMonitor(repo repository.AlertRepository, api aviasales.FlightIntegrationAPI, rates *currency.Service, notifier Notifier, cfg MonitorConfig) *Monitor {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultCheckInterval
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	host, _ := os.Hostname()
+
+	return &Monitor{
+		repo:          repo,
+		searcher:      provider.NewAviasalesProvider(api, search.DefaultPollAttempts, search.DefaultPollInterval),
+		rates:         rates,
+		notifier:      notifier,
+		limiter:       NewLimiter(cfg.SearchesPerMinute),
+		holder:        fmt.Sprintf("%s-%d", host, os.Getpid()),
+		checkInterval: cfg.CheckInterval,
+		pollInterval:  cfg.PollInterval,
+		leaseTTL:      max(3*cfg.PollInterval, minLeaseTTL),
+	}
+}
+
+// Start checks alerts in the background until ctx is done, then gives up the lease
+func (m *Monitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.pollInterval)
+		defer ticker.Stop()
+		for {
+			m.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				m.release()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce checks every due alert if this instance holds or can take the lease
+func (m *Monitor) RunOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		if !m.hold(ctx) {
+			return
+		}
+		due, err := m.repo.ClaimDueAlerts(ctx, time.Now().Add(m.checkInterval), claimBatchSize)
+		if err != nil {
+			log.Printf("[Monitor] claim due alerts: %v", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+
+		for _, alert := range due {
+			err := m.check(ctx, *alert)
+			if err == nil {
+				continue
+			}
+			log.Printf("[Monitor] alert %d: %v", alert.AlertId, err)
+			if errors.Is(err, errLeaseLost) || ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// hold takes or renews the lease and reports whether this instance has it
+func (m *Monitor) hold(ctx context.Context) bool {
+	ok, err := m.repo.AcquireLease(ctx, leaseName, m.holder, m.leaseTTL)
+	if err != nil {
+		log.Printf("[Monitor] acquire lease: %v", err)
+		ok = false
+	}
+	if ok != m.leader {
+		log.Printf("[Monitor] %s lease %s: holding=%t", m.holder, leaseName, ok)
+		m.leader = ok
+	}
+	return ok
+}
+
+func (m *Monitor) release() {
+	if !m.leader {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.repo.ReleaseLease(ctx, leaseName, m.holder); err != nil {
+		log.Printf("[Monitor] release lease: %v", err)
+	}
+}
+
+// check searches each remaining departure date of an alert, records the cheapest fare and sends a
+// notification for a new low at or below the threshold. Alerts whose dates have all passed are
+// switched off.
+func (m *Monitor) check(ctx context.Context, alert models.PriceAlert) error {
+	dates := departureDates(alert)
+	if len(dates) == 0 {
+		return m.repo.DeactivatePriceAlert(ctx, alert.AlertId)
+	}
+
+	result := models.PriceCheck{
+		AlertId:     alert.AlertId,
+		Currency:    alert.Currency,
+		NextCheckAt: time.Now().Add(m.checkInterval),
+	}
+	var failures []string
+	for _, date := range dates {
+		if err := m.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if !m.hold(ctx) {
+			return errLeaseLost
+		}
+
+		query := alertQuery(alert, date)
+		price, searchID, err := m.cheapest(ctx, query, alert.Currency)
+		if err != nil {
+			failures = append(failures, date+": "+err.Error())
+			// an open breaker means the supplier is shedding load; leave the rest for the next check
+			if errors.Is(err, aviasales.ErrCircuitOpen) {
+				break
+			}
+			continue
+		}
+		if price > 0 && (result.Price == 0 || price < result.Price) {
+			result.Price, result.SearchId = price, searchID
+			result.DepartureDate = query.Segments[0].Date
+			if len(query.Segments) == 2 {
+				result.ReturnDate = query.Segments[1].Date
+			}
+		}
+	}
+	result.Error = strings.Join(failures, "; ")
+
+	if err := m.repo.RecordPriceCheck(ctx, result); err != nil {
+		return err
+	}
+	if result.Price == 0 || result.Price > alert.Threshold {
+		return nil
+	}
+	if alert.NotifiedPrice != nil && result.Price >= *alert.NotifiedPrice {
+		return nil
+	}
+
+	n := Notification{
+		Alert:         alert,
+		Price:         result.Price,
+		Currency:      alert.Currency,
+		DepartureDate: result.DepartureDate,
+		ReturnDate:    result.ReturnDate,
+		SearchId:      result.SearchId,
+	}
+	if err := m.notifier.Notify(ctx, n); err != nil {
+		// left unmarked, so the next check notifies again
+		return fmt.Errorf("notify: %w", err)
+	}
+	return m.repo.MarkAlertNotified(ctx, alert.AlertId, result.Price)
+}
+
+// cheapest returns the lowest fare of a search converted to currency; zero when nothing was found
+func (m *Monitor) cheapest(ctx context.Context, query models.FlightQuery, currencyCode string) (money.Amount, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	results, err := m.searcher.Search(ctx, query)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var best models.Fare
+	for _, it := range results.Itineraries {
+		if fare, ok := it.BestFare(); ok && (best.UnifiedPrice == 0 || fare.UnifiedPrice < best.UnifiedPrice) {
+			best = fare
+		}
+	}
+	if best.UnifiedPrice == 0 {
+		return 0, "", nil
+	}
+
+	price, err := m.rates.Convert(ctx, best.UnifiedPrice, results.Currency, currencyCode)
+	if err != nil {
+		return 0, "", err
+	}
+	return price, best.SearchID, nil
+}
+
+// departureDates lists the dates of the alert range that have not passed yet
+func departureDates(alert models.PriceAlert) []string {
+	from, err := time.Parse(time.DateOnly, alert.DepartFrom)
+	if err != nil {
+		return nil
+	}
+	to, err := time.Parse(time.DateOnly, alert.DepartTo)
+	if err != nil {
+		return nil
+	}
+	if t := today(); from.Before(t) {
+		from = t
+	}
+
+	var dates []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format(time.DateOnly))
+	}
+	return dates
+}
+
+// alertQuery is the search for one departure date of an alert
+func alertQuery(alert models.PriceAlert, date string) models.FlightQuery {
+	segments := []models.QuerySegment{{Origin: alert.Origin, Destination: alert.Destination, Date: date}}
+	if alert.StayDays != nil {
+		d, _ := time.Parse(time.DateOnly, date)
+		segments = append(segments, models.QuerySegment{
+			Origin:      alert.Destination,
+			Destination: alert.Origin,
+			Date:        d.AddDate(0, 0, *alert.StayDays).Format(time.DateOnly),
+		})
+	}
+
+	ip := alert.UserIP
+	if ip == "" {
+		ip = fallbackUserIP
+	}
+	return models.FlightQuery{
+		Segments:  segments,
+		Adults:    alert.Adults,
+		TripClass: alert.TripClass,
+		Locale:    "en",
+		UserIP:    ip,
+	}
+}
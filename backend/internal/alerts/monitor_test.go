@@ -0,0 +1,276 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/repository"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/money"
+)
+
+// fakeAlertRepo records what the monitor writes; leases says how many lease calls succeed
+type fakeAlertRepo struct {
+	repository.AlertRepository
+
+	mu          sync.Mutex
+	due         [][]*models.PriceAlert
+	leases      int
+	leaseCalls  int
+	claims      int
+	checks      []models.PriceCheck
+	notified    map[int64]money.Amount
+	deactivated []int64
+}
+
+func (r *fakeAlertRepo) ClaimDueAlerts(ctx context.Context, nextCheckAt time.Time, limit int) ([]*models.PriceAlert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.claims++
+	if len(r.due) == 0 {
+		return nil, nil
+	}
+	batch := r.due[0]
+	r.due = r.due[1:]
+	return batch, nil
+}
+
+func (r *fakeAlertRepo) RecordPriceCheck(ctx context.Context, check models.PriceCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+func (r *fakeAlertRepo) MarkAlertNotified(ctx context.Context, alertId int64, price money.Amount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.notified == nil {
+		r.notified = make(map[int64]money.Amount)
+	}
+	r.notified[alertId] = price
+	return nil
+}
+
+func (r *fakeAlertRepo) DeactivatePriceAlert(ctx context.Context, alertId int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deactivated = append(r.deactivated, alertId)
+	return nil
+}
+
+func (r *fakeAlertRepo) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leaseCalls++
+	return r.leaseCalls <= r.leases, nil
+}
+
+func (r *fakeAlertRepo) ReleaseLease(ctx context.Context, name, holder string) error {
+	return nil
+}
+
+// stubProvider answers each departure date with a price in USD or an error
+type stubProvider struct {
+	mu       sync.Mutex
+	prices   map[string]money.Amount
+	errs     map[string]error
+	searched []string
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	date := query.Segments[0].Date
+	p.mu.Lock()
+	p.searched = append(p.searched, date)
+	p.mu.Unlock()
+
+	if err := p.errs[date]; err != nil {
+		return nil, err
+	}
+	result := &models.FlightSearchResult{Currency: "USD"}
+	if price := p.prices[date]; price > 0 {
+		result.Itineraries = []models.Itinerary{{Fares: []models.Fare{{UnifiedPrice: price, SearchID: "search-" + date}}}}
+	}
+	return result, nil
+}
+
+func testMonitor(repo *fakeAlertRepo, p *stubProvider, notifier Notifier) *Monitor {
+	return &Monitor{
+		repo:          repo,
+		searcher:      p,
+		rates:         currency.NewService(currency.NewStaticProvider(currency.DefaultRates())),
+		notifier:      notifier,
+		limiter:       NewLimiter(60000),
+		holder:        "test",
+		checkInterval: time.Hour,
+		pollInterval:  time.Minute,
+		leaseTTL:      minLeaseTTL,
+	}
+}
+
+type recordingNotifier struct {
+	sent []Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, note Notification) error {
+	n.sent = append(n.sent, note)
+	return nil
+}
+
+// day is a date relative to today in the format alerts use
+func day(offset int) string {
+	return today().AddDate(0, 0, offset).Format(time.DateOnly)
+}
+
+func testAlert(id int64, from, to string) *models.PriceAlert {
+	return &models.PriceAlert{AlertId: id, Origin: "DEL", Destination: "BOM", DepartFrom: from, DepartTo: to, Adults: 1, Currency: "USD", Threshold: money.FromUnits(100, 0), Active: true}
+}
+
+func TestRunOnceStopsWhenTheLeaseIsLost(t *testing.T) {
+	// the round takes the lease, then keeps it for the first date of the first alert only
+	repo := &fakeAlertRepo{leases: 2, due: [][]*models.PriceAlert{{testAlert(1, day(1), day(2)), testAlert(2, day(1), day(1))}}}
+	p := &stubProvider{prices: map[string]money.Amount{day(1): money.FromUnits(90, 0)}}
+	notifier := &recordingNotifier{}
+
+	testMonitor(repo, p, notifier).RunOnce(context.Background())
+
+	if len(p.searched) != 1 || p.searched[0] != day(1) {
+		t.Fatalf("searched %v, want only the first date", p.searched)
+	}
+	if len(repo.checks) != 0 || len(notifier.sent) != 0 || repo.claims != 1 {
+		t.Fatalf("recorded %d checks, sent %d notifications and claimed %d times after losing the lease", len(repo.checks), len(notifier.sent), repo.claims)
+	}
+}
+
+func TestRunOnceWithoutTheLease(t *testing.T) {
+	repo := &fakeAlertRepo{due: [][]*models.PriceAlert{{testAlert(1, day(1), day(1))}}}
+	p := &stubProvider{}
+
+	testMonitor(repo, p, &recordingNotifier{}).RunOnce(context.Background())
+
+	if repo.claims != 0 || len(p.searched) != 0 {
+		t.Fatalf("claimed %d times and searched %v without the lease", repo.claims, p.searched)
+	}
+}
+
+func TestCheckNotifiesOnlyOnANewLowWithinThreshold(t *testing.T) {
+	amount := func(units int64) *money.Amount {
+		a := money.FromUnits(units, 0)
+		return &a
+	}
+
+	tests := []struct {
+		name     string
+		prices   []int64
+		notified *money.Amount
+		want     money.Amount
+	}{
+		{name: "below threshold", prices: []int64{120, 90}, want: money.FromUnits(90, 0)},
+		{name: "at threshold", prices: []int64{100}, want: money.FromUnits(100, 0)},
+		{name: "above threshold", prices: []int64{110, 130}},
+		{name: "nothing found", prices: []int64{0}},
+		{name: "lower than last notified", prices: []int64{90}, notified: amount(95), want: money.FromUnits(90, 0)},
+		{name: "same as last notified", prices: []int64{90}, notified: amount(90)},
+		{name: "higher than last notified", prices: []int64{90}, notified: amount(80)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &stubProvider{prices: map[string]money.Amount{}}
+			for i, price := range tt.prices {
+				p.prices[day(i+1)] = money.FromUnits(price, 0)
+			}
+			alert := testAlert(7, day(1), day(len(tt.prices)))
+			alert.NotifiedPrice = tt.notified
+			repo := &fakeAlertRepo{leases: 100}
+			notifier := &recordingNotifier{}
+
+			if err := testMonitor(repo, p, notifier).check(context.Background(), *alert); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(repo.checks) != 1 {
+				t.Fatalf("recorded %d checks, want 1", len(repo.checks))
+			}
+			if tt.want == 0 {
+				if len(notifier.sent) != 0 || len(repo.notified) != 0 {
+					t.Fatalf("notified %+v", notifier.sent)
+				}
+				return
+			}
+			if len(notifier.sent) != 1 || notifier.sent[0].Price != tt.want || repo.notified[7] != tt.want {
+				t.Fatalf("notified %+v, marked %v, want %s", notifier.sent, repo.notified, tt.want)
+			}
+			if n := notifier.sent[0]; n.SearchId != "search-"+n.DepartureDate {
+				t.Fatalf("notification for %s carries search %s", n.DepartureDate, n.SearchId)
+			}
+		})
+	}
+}
+
+func TestCheckDeactivatesPastAlerts(t *testing.T) {
+	repo := &fakeAlertRepo{leases: 100}
+	p := &stubProvider{}
+
+	if err := testMonitor(repo, p, &recordingNotifier{}).check(context.Background(), *testAlert(3, day(-5), day(-1))); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.deactivated) != 1 || repo.deactivated[0] != 3 || len(p.searched) != 0 || len(repo.checks) != 0 {
+		t.Fatalf("deactivated %v, searched %v, recorded %d checks", repo.deactivated, p.searched, len(repo.checks))
+	}
+}
+
+func TestCheckStopsAtAnOpenBreaker(t *testing.T) {
+	repo := &fakeAlertRepo{leases: 100}
+	p := &stubProvider{
+		prices: map[string]money.Amount{day(1): money.FromUnits(90, 0)},
+		errs: map[string]error{
+			day(2): errors.New("timeout"),
+			day(3): fmt.Errorf("search: %w", aviasales.ErrCircuitOpen),
+		},
+	}
+
+	if err := testMonitor(repo, p, &recordingNotifier{}).check(context.Background(), *testAlert(4, day(1), day(5))); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.searched) != 3 {
+		t.Fatalf("searched %v, want the dates up to the open breaker", p.searched)
+	}
+	check := repo.checks[0]
+	if check.Price != money.FromUnits(90, 0) || check.DepartureDate != day(1) {
+		t.Fatalf("recorded %+v, want the fare found before the breaker opened", check)
+	}
+	if !strings.Contains(check.Error, day(2)+": timeout") || !strings.Contains(check.Error, day(3)+": ") {
+		t.Fatalf("recorded error %q, want both failures", check.Error)
+	}
+}
+
+func TestDepartureDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{name: "future range", from: day(2), to: day(4), want: []string{day(2), day(3), day(4)}},
+		{name: "started in the past", from: day(-3), to: day(1), want: []string{day(0), day(1)}},
+		{name: "passed", from: day(-3), to: day(-1), want: nil},
+		{name: "reversed", from: day(4), to: day(2), want: nil},
+		{name: "invalid", from: "tomorrow", to: day(2), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := departureDates(models.PriceAlert{DepartFrom: tt.from, DepartTo: tt.to})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+// alert delivery channels; a channel is offered to users only when the Router has a notifier for it
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// Notification tells a user that their alert found a fare at or below its threshold
+type Notification struct {
+	Alert         models.PriceAlert `json:"alert"`
+	Price         money.Amount      `json:"price"`
+	Currency      string            `json:"currency"`
+	DepartureDate string            `json:"departure_date"`
+	ReturnDate    string            `json:"return_date,omitempty"`
+	SearchId      string            `json:"search_id,omitempty"`
+}
+
+// Subject is a one-line summary of the notification
+func (n Notification) Subject() string {
+	return fmt.Sprintf("%s-%s now %s %s", n.Alert.Origin, n.Alert.Destination, n.Price, n.Currency)
+}
+
+// Body describes the fare found and the alert that matched it
+func (n Notification) Body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A fare from %s to %s departing %s", n.Alert.Origin, n.Alert.Destination, n.DepartureDate)
+	if n.ReturnDate != "" {
+		fmt.Fprintf(&b, " and returning %s", n.ReturnDate)
+	}
+	fmt.Fprintf(&b, " costs %s %s, at or below your alert price of %s %s.\r\n", n.Price, n.Currency,
+		n.Alert.Threshold, n.Alert.Currency)
+	return b.String()
+}
+
+// Notifier delivers a notification to the target of its alert
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier writes notifications to the application log
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("[PriceAlert] alert=%d user=%d %s", n.Alert.AlertId, n.Alert.UserId, n.Subject())
+	return nil
+}
+
+type webhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier posts notifications as JSON to the URL in the alert target. Targets that
+// resolve to non-public addresses are refused and redirects are not followed.
+func NewWebhookNotifier() Notifier {
+	return &webhookNotifier{client: newWebhookClient()}
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Alert.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// redirects are returned rather than followed, so a 3xx is a failed delivery too
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("post webhook: HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+type smtpNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPNotifier mails notifications to the address in the alert target through the server at
+// host:port. Credentials are optional; without them the server must accept unauthenticated mail.
+func NewSMTPNotifier(host, port, user, password, from string) Notifier {
+	n := &smtpNotifier{addr: net.JoinHostPort(host, port), from: from}
+	if user != "" {
+		n.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return n
+}
+
+func (s *smtpNotifier) Notify(ctx context.Context, n Notification) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.Alert.Target)
+	fmt.Fprintf(&msg, "Subject: Price alert: %s\r\n", n.Subject())
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(n.Body())
+
+	// net/smtp takes no context; send in the background and stop waiting once ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, s.from, []string{n.Alert.Target}, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ErrUnknownChannel is returned for notifications on a channel the Router has no notifier for
+var ErrUnknownChannel = errors.New("alerts: unsupported notification channel")
+
+// Router delivers each notification through the notifier registered for its alert's channel
+type Router struct {
+	channels map[string]Notifier
+}
+
+func NewRouter() *Router {
+	return &Router{channels: make(map[string]Notifier)}
+}
+
+// Register adds or replaces the notifier of a channel
+func (r *Router) Register(channel string, n Notifier) *Router {
+	r.channels[channel] = n
+	return r
+}
+
+// Supports reports whether alerts can be delivered on the channel
+func (r *Router) Supports(channel string) bool {
+	_, ok := r.channels[channel]
+	return ok
+}
+
+func (r *Router) Notify(ctx context.Context, n Notification) error {
+	notifier, ok := r.channels[n.Alert.Channel]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownChannel, n.Alert.Channel)
+	}
+	return notifier.Notify(ctx, n)
+}
@@ -0,0 +1,178 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultCurrency is the currency of alerts that name none
+	DefaultCurrency = "USD"
+	// MaxAlertsPerUser bounds the background searches one user can cause
+	MaxAlertsPerUser = 20
+	// MaxDepartureDays is the widest departure range of an alert; every date is one search per check
+	MaxDepartureDays = 7
+	// DefaultHistoryLimit is the number of checks the history api returns
+	DefaultHistoryLimit = 100
+)
+
+var (
+	// ErrUnavailable is returned by every call when no database is configured
+	ErrUnavailable = errors.New("alerts: price alerts need a database")
+	// ErrNotFound is returned for alerts that do not exist or belong to another user
+	ErrNotFound = errors.New("alerts: price alert not found")
+	// ErrInvalidAlert wraps the reason an alert was rejected
+	ErrInvalidAlert = errors.New("alerts: invalid price alert")
+	// ErrTooManyAlerts is returned when a user already has MaxAlertsPerUser alerts
+	ErrTooManyAlerts = errors.New("alerts: too many price alerts")
+)
+
+// Service manages the price alerts of users. A Service without a repository answers every call
+// with ErrUnavailable.
+type Service struct {
+	repo     repository.AlertRepository
+	notifier *Router
+	rates    *currency.Service
+}
+
+func NewService(repo repository.AlertRepository, notifier *Router, rates *currency.Service) *Service {
+	return &Service{repo: repo, notifier: notifier, rates: rates}
+}
+
+// Create checks and stores a new alert of alert.UserId
+func (s *Service) Create(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error) {
+	if s.repo == nil {
+		return nil, ErrUnavailable
+	}
+	if err := s.validate(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountPriceAlerts(ctx, alert.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxAlertsPerUser {
+		return nil, fmt.Errorf("%w: at most %d per user", ErrTooManyAlerts, MaxAlertsPerUser)
+	}
+	return s.repo.CreatePriceAlert(ctx, alert)
+}
+
+// Update replaces the settings of an alert of alert.UserId
+func (s *Service) Update(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error) {
+	if s.repo == nil {
+		return nil, ErrUnavailable
+	}
+	if err := s.validate(ctx, alert); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.UpdatePriceAlert(ctx, alert)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return updated, err
+}
+
+func (s *Service) Get(ctx context.Context, userId, alertId int64) (*models.PriceAlert, error) {
+	if s.repo == nil {
+		return nil, ErrUnavailable
+	}
+	alert, err := s.repo.GetPriceAlert(ctx, userId, alertId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return alert, err
+}
+
+func (s *Service) List(ctx context.Context, userId int64) ([]*models.PriceAlert, error) {
+	if s.repo == nil {
+		return nil, ErrUnavailable
+	}
+	return s.repo.ListPriceAlerts(ctx, userId)
+}
+
+func (s *Service) Delete(ctx context.Context, userId, alertId int64) error {
+	if s.repo == nil {
+		return ErrUnavailable
+	}
+	deleted, err := s.repo.DeletePriceAlert(ctx, userId, alertId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// History returns the latest checks of an alert of the user, newest first
+func (s *Service) History(ctx context.Context, userId, alertId int64) ([]*models.PricePoint, error) {
+	if _, err := s.Get(ctx, userId, alertId); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPriceHistory(ctx, alertId, DefaultHistoryLimit)
+}
+
+// validate applies the rules that cannot be expressed as validation tags
+func (s *Service) validate(ctx context.Context, a models.PriceAlert) error {
+	if strings.EqualFold(a.Origin, a.Destination) {
+		return fmt.Errorf("%w: origin and destination must differ", ErrInvalidAlert)
+	}
+
+	from, err := time.Parse(time.DateOnly, a.DepartFrom)
+	if err != nil {
+		return fmt.Errorf("%w: invalid depart_from %q", ErrInvalidAlert, a.DepartFrom)
+	}
+	to, err := time.Parse(time.DateOnly, a.DepartTo)
+	if err != nil {
+		return fmt.Errorf("%w: invalid depart_to %q", ErrInvalidAlert, a.DepartTo)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("%w: depart_to is before depart_from", ErrInvalidAlert)
+	}
+	if to.Before(today()) {
+		return fmt.Errorf("%w: every departure date has passed", ErrInvalidAlert)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxDepartureDays {
+		return fmt.Errorf("%w: at most %d departure dates, got %d", ErrInvalidAlert, MaxDepartureDays, days)
+	}
+
+	ok, err := s.rates.Supported(ctx, a.Currency)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: unsupported currency %s", ErrInvalidAlert, a.Currency)
+	}
+
+	if !s.notifier.Supports(a.Channel) {
+		return fmt.Errorf("%w: channel %s is not available", ErrInvalidAlert, a.Channel)
+	}
+	switch a.Channel {
+	case ChannelWebhook:
+		if err := CheckWebhookURL(ctx, a.Target); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAlert, err)
+		}
+	case ChannelEmail:
+		addr, err := mail.ParseAddress(a.Target)
+		if err != nil || addr.Address != a.Target {
+			return fmt.Errorf("%w: email target must be a plain email address", ErrInvalidAlert)
+		}
+	}
+	return nil
+}
+
+// today is the current date in UTC, which departure dates are compared against
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,128 @@
+package alerts
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// StubMessage is one mail accepted by a StubSMTPServer
+type StubMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// StubSMTPServer accepts mail for local development so email alerts can be tried without a mail
+// provider. It speaks enough SMTP for net/smtp, accepts any credentials, logs every message and
+// keeps them in memory.
+type StubSMTPServer struct {
+	mu       sync.Mutex
+	messages []StubMessage
+}
+
+func NewStubSMTPServer() *StubSMTPServer {
+	return &StubSMTPServer{}
+}
+
+// Serve accepts connections on l until it is closed
+func (s *StubSMTPServer) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Messages returns the mail accepted so far
+func (s *StubSMTPServer) Messages() []StubMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StubMessage(nil), s.messages...)
+}
+
+func (s *StubSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	reply := func(line string) bool {
+		return tp.PrintfLine("%s", line) == nil
+	}
+
+	var msg StubMessage
+	if !reply("220 stopover fake smtp ready") {
+		return
+	}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if !reply("250-stopover\r\n250-8BITMIME\r\n250 AUTH PLAIN LOGIN") {
+				return
+			}
+		case "HELO", "NOOP":
+			if !reply("250 OK") {
+				return
+			}
+		case "AUTH":
+			if !reply("235 authenticated") {
+				return
+			}
+		case "MAIL":
+			msg = StubMessage{From: address(arg)}
+			if !reply("250 OK") {
+				return
+			}
+		case "RCPT":
+			msg.To = append(msg.To, address(arg))
+			if !reply("250 OK") {
+				return
+			}
+		case "DATA":
+			if !reply("354 end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			log.Printf("[StubSMTP] from=%s to=%s\n%s", msg.From, strings.Join(msg.To, ","), msg.Data)
+			msg = StubMessage{}
+			if !reply("250 OK: queued") {
+				return
+			}
+		case "RSET":
+			msg = StubMessage{}
+			if !reply("250 OK") {
+				return
+			}
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			if !reply("502 command not implemented") {
+				return
+			}
+		}
+	}
+}
+
+// address reads the mailbox of a MAIL FROM:<a> or RCPT TO:<a> argument
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
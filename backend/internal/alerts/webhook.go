@@ -0,0 +1,76 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for webhooks that resolve to an address users must not reach
+// through the server, such as loopback, private networks or cloud metadata endpoints
+var ErrPrivateAddress = errors.New("alerts: webhook address is not public")
+
+// CheckWebhookURL accepts http and https urls whose host resolves only to public addresses.
+// Delivery checks the address again when it connects, since the name may resolve differently
+// by then.
+func CheckWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("webhook target must be an http or https url")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("webhook host %s does not resolve", u.Hostname())
+	}
+	for _, a := range addrs {
+		if !publicIP(a.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, u.Hostname(), a.IP)
+		}
+	}
+	return nil
+}
+
+// publicIP reports whether ip is a unicast address outside loopback, link-local, private and
+// unspecified ranges
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsPrivate() || ip.IsUnspecified())
+}
+
+// dialPublicOnly is a net.Dialer Control hook refusing connections to non-public addresses. It
+// runs after name resolution for every address dialed, so DNS rebinding cannot get past it.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// newWebhookClient only connects to public addresses, never through a proxy, and does not
+// follow redirects, which could otherwise point it back at an internal address
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialPublicOnly}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stopover.backend/internal/models"
+)
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url         string
+		wantPrivate bool
+		wantErr     bool
+	}{
+		{url: "https://93.184.216.34/hooks/price"},
+		{url: "http://[2606:4700::1111]:8080/hook"},
+		{url: "http://127.0.0.1/hook", wantPrivate: true},
+		{url: "http://localhost:8080/hook", wantPrivate: true},
+		{url: "http://[::1]/hook", wantPrivate: true},
+		{url: "http://10.1.2.3/hook", wantPrivate: true},
+		{url: "http://172.16.0.9/hook", wantPrivate: true},
+		{url: "http://192.168.1.1/hook", wantPrivate: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantPrivate: true},
+		{url: "http://[fe80::1]/hook", wantPrivate: true},
+		{url: "http://[fd00::1]/hook", wantPrivate: true},
+		{url: "http://0.0.0.0/hook", wantPrivate: true},
+		{url: "http://[::ffff:127.0.0.1]/hook", wantPrivate: true},
+		{url: "ftp://93.184.216.34/hook", wantErr: true},
+		{url: "http:///hook", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckWebhookURL(context.Background(), tt.url)
+			if got := errors.Is(err, ErrPrivateAddress); got != tt.wantPrivate {
+				t.Errorf("error = %v, want private address rejected: %t", err, tt.wantPrivate)
+			}
+			if wantErr := tt.wantErr || tt.wantPrivate; (err != nil) != wantErr {
+				t.Errorf("error = %v, wantErr %t", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierRefusesPrivateAddresses(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+
+	// the target passed validation earlier, or its name now resolves somewhere else
+	err := NewWebhookNotifier().Notify(context.Background(), Notification{
+		Alert: models.PriceAlert{Channel: ChannelWebhook, Target: srv.URL},
+	})
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("error = %v, want ErrPrivateAddress", err)
+	}
+	if hits != 0 {
+		t.Fatalf("webhook on a loopback address was called %d times", hits)
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://93.184.216.34/hook", nil)
+	if err := newWebhookClient().CheckRedirect(req, nil); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Fatalf("CheckRedirect = %v, want http.ErrUseLastResponse", err)
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stopover.backend/internal/alerts"
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListPriceAlerts handles GET /api/alerts and returns the alerts of the signed-in user
+func (f *FlightHandler) ListPriceAlerts(c *gin.Context) {
+	user := common.GetUserFromContext(c.Request.Context())
+
+	list, err := f.Alerts.List(c.Request.Context(), user.UserId)
+	if err != nil {
+		alertError(c, "ListPriceAlerts", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": list})
+}
+
+// CreatePriceAlert handles POST /api/alerts. The alert is checked in the background from then on
+// and notifies through its channel when the cheapest fare over its departure dates reaches the threshold.
+func (f *FlightHandler) CreatePriceAlert(c *gin.Context) {
+	var req models.PriceAlertRequest
+	if err := common.ValidateRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alert := newPriceAlert(c, req)
+
+	created, err := f.Alerts.Create(c.Request.Context(), alert)
+	if err != nil {
+		alertError(c, "CreatePriceAlert", err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetPriceAlert handles GET /api/alerts/:id
+func (f *FlightHandler) GetPriceAlert(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+	user := common.GetUserFromContext(c.Request.Context())
+
+	alert, err := f.Alerts.Get(c.Request.Context(), user.UserId, id)
+	if err != nil {
+		alertError(c, "GetPriceAlert", err)
+		return
+	}
+	c.JSON(http.StatusOK, alert)
+}
+
+// UpdatePriceAlert handles PUT /api/alerts/:id and replaces the settings of an alert; the body is
+// the same as when creating one
+func (f *FlightHandler) UpdatePriceAlert(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+	var req models.PriceAlertRequest
+	if err := common.ValidateRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alert := newPriceAlert(c, req)
+	alert.AlertId = id
+
+	updated, err := f.Alerts.Update(c.Request.Context(), alert)
+	if err != nil {
+		alertError(c, "UpdatePriceAlert", err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeletePriceAlert handles DELETE /api/alerts/:id
+func (f *FlightHandler) DeletePriceAlert(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+	user := common.GetUserFromContext(c.Request.Context())
+
+	if err := f.Alerts.Delete(c.Request.Context(), user.UserId, id); err != nil {
+		alertError(c, "DeletePriceAlert", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PriceAlertHistory handles GET /api/alerts/:id/history and returns the latest checks of an alert
+func (f *FlightHandler) PriceAlertHistory(c *gin.Context) {
+	id, ok := alertID(c)
+	if !ok {
+		return
+	}
+	user := common.GetUserFromContext(c.Request.Context())
+
+	history, err := f.Alerts.History(c.Request.Context(), user.UserId, id)
+	if err != nil {
+		alertError(c, "PriceAlertHistory", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alert_id": id, "history": history})
+}
+
+// newPriceAlert builds an alert of the signed-in user from a request, filling in its defaults
+func newPriceAlert(c *gin.Context, req models.PriceAlertRequest) models.PriceAlert {
+	alert := models.PriceAlert{
+		UserId:      common.GetUserFromContext(c.Request.Context()).UserId,
+		Origin:      strings.ToUpper(req.Origin),
+		Destination: strings.ToUpper(req.Destination),
+		DepartFrom:  req.DepartFrom,
+		DepartTo:    req.DepartTo,
+		StayDays:    req.StayDays,
+		Adults:      req.Adults,
+		TripClass:   cabinTripClass[req.Cabin],
+		Currency:    strings.ToUpper(req.Currency),
+		Threshold:   req.Threshold,
+		Channel:     req.Channel,
+		Target:      strings.TrimSpace(req.Target),
+		UserIP:      c.ClientIP(),
+		Active:      req.Active == nil || *req.Active,
+	}
+	if alert.DepartTo == "" {
+		alert.DepartTo = alert.DepartFrom
+	}
+	if alert.Adults == 0 {
+		alert.Adults = 1
+	}
+	if alert.TripClass == "" {
+		alert.TripClass = cabinTripClass["economy"]
+	}
+	if alert.Currency == "" {
+		alert.Currency = alerts.DefaultCurrency
+	}
+	return alert
+}
+
+// alertID reads the alert id path param; on failure the error response has already been written
+func alertID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// alertError maps price alert service errors to responses
+func alertError(c *gin.Context, caller string, err error) {
+	switch {
+	case errors.Is(err, alerts.ErrInvalidAlert), errors.Is(err, alerts.ErrTooManyAlerts):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, alerts.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, alerts.ErrUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		log.Printf("[%s] %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "price alert request failed"})
+	}
+}
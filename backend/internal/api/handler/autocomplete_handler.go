@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stopover.backend/internal/places"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 20
+	maxAutocompleteQuery     = 64
+
+	defaultNearbyRadiusKm = 150
+	maxNearbyRadiusKm     = 500
+	defaultNearbyLimit    = 5
+	maxNearbyLimit        = 20
+)
+
+type airportItem struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	City     string `json:"city"`
+	CityCode string `json:"city_code"`
+	Country  string `json:"country"`
+}
+
+func newAirportItem(a places.Airport) airportItem {
+	return airportItem{Code: a.Code, Name: a.Name, City: a.City, CityCode: a.CityCode, Country: a.Country}
+}
+
+// AirportsAutocomplete handles GET /api/airports/autocomplete?q= from the in-process airport index.
+// q matches airport and city codes, city and airport names and their other spellings; limit caps
+// the number of suggestions. A city with several airports comes back as one item of type "city"
+// carrying its airports, so the city code can be searched as a whole.
+func (f *FlightHandler) AirportsAutocomplete(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing query parameter 'q'"})
+		return
+	}
+	if len(q) > maxAutocompleteQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is too long"})
+		return
+	}
+
+	limit := defaultAutocompleteLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAutocompleteLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxAutocompleteLimit)})
+			return
+		}
+		limit = n
+	}
+
+	type item struct {
+		Type string `json:"type"`
+		airportItem
+		Airports []airportItem `json:"airports,omitempty"`
+	}
+
+	suggestions := f.Places.Suggest(q, limit)
+	res := make([]item, 0, len(suggestions))
+	for _, s := range suggestions {
+		if s.City == nil {
+			res = append(res, item{Type: "airport", airportItem: newAirportItem(*s.Airport)})
+			continue
+		}
+		city := item{
+			Type:        "city",
+			airportItem: airportItem{Code: s.City.Code, Name: s.City.Name, City: s.City.Name, CityCode: s.City.Code, Country: s.City.Country},
+			Airports:    make([]airportItem, 0, len(s.Airports)),
+		}
+		for _, a := range s.Airports {
+			city.Airports = append(city.Airports, newAirportItem(a))
+		}
+		res = append(res, city)
+	}
+
+	// the dataset only changes on restart
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{"items": res})
+}
+
+// NearbyAirports handles GET /api/airports/nearby, listing airports within radius km (default 150,
+// at most 500) of an airport or city given by code, or of a point given by lat and lon. The
+// airport or city itself is left out.
+func (f *FlightHandler) NearbyAirports(c *gin.Context) {
+	radius := float64(defaultNearbyRadiusKm)
+	if v := c.Query("radius"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r > maxNearbyRadiusKm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and " + strconv.Itoa(maxNearbyRadiusKm) + " km"})
+			return
+		}
+		radius = r
+	}
+
+	limit := defaultNearbyLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNearbyLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxNearbyLimit)})
+			return
+		}
+		limit = n
+	}
+
+	var found []places.NearbyAirport
+	if code := strings.TrimSpace(c.Query("code")); code != "" {
+		var ok bool
+		if found, ok = f.Places.NearbyAirports(code, radius, limit); !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown airport or city " + strings.ToUpper(code)})
+			return
+		}
+	} else {
+		lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, errLon := strconv.ParseFloat(c.Query("lon"), 64)
+		if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "either code or valid lat and lon are required"})
+			return
+		}
+		found = f.Places.Nearby(lat, lon, radius, limit)
+	}
+
+	type item struct {
+		airportItem
+		DistanceKm float64 `json:"distance_km"`
+	}
+
+	res := make([]item, 0, len(found))
+	for _, n := range found {
+		res = append(res, item{airportItem: newAirportItem(n.Airport), DistanceKm: math.Round(n.DistanceKm*10) / 10})
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{"items": res})
+}
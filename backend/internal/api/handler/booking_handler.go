@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	searchIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+	termPattern     = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+)
+
+// BookFlight handles GET /api/flights/:searchId/book?term= and resolves the agency deeplink for
+// one fare. searchId and term are the search_id and booking_token of a fare in the search results.
+// With redirect=true the client is sent straight to the agency; agencies that need a POST cannot
+// be redirected to, so their form params are always returned as JSON. Every resolved link is
+// recorded as a click-out; passing the resultId the fare came from records its route and price.
+func (f *FlightHandler) BookFlight(c *gin.Context) {
+	searchID := c.Param("searchId")
+	term := c.Query("term")
+	if !searchIDPattern.MatchString(searchID) || !termPattern.MatchString(term) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid search id or term"})
+		return
+	}
+
+	link, err := f.FlightApi.GetBookingLink(c.Request.Context(), searchID, term)
+	if err != nil {
+		log.Printf("[BookFlight] %s/%s: %v", searchID, term, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to resolve booking link"})
+		return
+	}
+
+	// never redirect users to anything but a web page
+	agency, err := url.Parse(link.URL)
+	if err != nil || (agency.Scheme != "https" && agency.Scheme != "http") || agency.Host == "" {
+		log.Printf("[BookFlight] %s/%s: rejected agency url %q", searchID, term, link.URL)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "invalid booking link"})
+		return
+	}
+
+	click := models.ClickOut{
+		SearchId:      searchID,
+		ResultId:      c.Query("resultId"),
+		Term:          term,
+		Gate:          strconv.Itoa(link.GateID),
+		AgencyClickId: link.StrClickID,
+		AgencyURL:     link.URL,
+		UserIP:        c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		Referer:       c.Request.Referer(),
+	}
+	f.describeClick(c.Request.Context(), &click)
+	if user := common.GetUserFromContext(c.Request.Context()); user != nil {
+		click.UserId = user.UserId
+	}
+	if err := f.Tracker.RecordClick(c.Request.Context(), click); err != nil {
+		log.Printf("[BookFlight] failed to record click: %v", err)
+	}
+
+	if c.Query("redirect") == "true" && link.Method == http.MethodGet {
+		c.Redirect(http.StatusFound, link.URL)
+		return
+	}
+	c.JSON(http.StatusOK, link)
+}
+
+// describeClick fills in the route and price of the clicked fare from the cached result the user
+// booked from. Without a live result the tracker derives the route from the recorded search.
+func (f *FlightHandler) describeClick(ctx context.Context, click *models.ClickOut) {
+	if click.ResultId == "" {
+		return
+	}
+	results, ok := f.Results.Get(ctx, click.ResultId)
+	if !ok {
+		return
+	}
+	for _, it := range results.Itineraries {
+		for _, fare := range it.Fares {
+			if fare.SearchID != click.SearchId || fare.BookingToken != click.Term {
+				continue
+			}
+			if len(it.Legs) > 0 {
+				click.Origin, click.Destination = it.Legs[0].Origin, it.Legs[0].Destination
+			}
+			click.Gate = fare.Gate
+			click.Price, click.Currency = fare.UnifiedPrice, results.Currency
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stopover.backend/internal/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarTimeout bounds a whole calendar build; cells still searching when it expires report an error
+const calendarTimeout = 60 * time.Second
+
+// FlightCalendar handles GET /api/flights/calendar and returns the cheapest fare for every
+// departure/return date pair within flex days (default 3) of the requested dates
+func (f *FlightHandler) FlightCalendar(c *gin.Context) {
+	params, err := bindSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flex, err := strconv.Atoi(c.DefaultQuery("flex", strconv.Itoa(search.MaxCalendarFlexDays)))
+	if err != nil || flex < 0 || flex > search.MaxCalendarFlexDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid flex %q: expected 0-%d", c.Query("flex"), search.MaxCalendarFlexDays)})
+		return
+	}
+
+	currencyCode, ok := f.bindCurrencyQuery(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), calendarTimeout)
+	defer cancel()
+
+	calendar, err := f.Calendar.Build(ctx, newFlightQuery(c.ClientIP(), params), flex)
+	if err != nil {
+		log.Printf("Calendar error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !f.convertCalendar(c, calendar, currencyCode) {
+		return
+	}
+	c.JSON(http.StatusOK, calendar)
+}
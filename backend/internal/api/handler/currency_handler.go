@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currencyCodePattern matches ISO 4217 alphabetic codes
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// bindCurrencyQuery reads the optional currency param and checks that it can be converted to.
+// An empty currency keeps the supplier's unified currency. On failure the error response has
+// already been written.
+func (f *FlightHandler) bindCurrencyQuery(c *gin.Context) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if code == "" {
+		return "", true
+	}
+	if !currencyCodePattern.MatchString(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency: expected a 3-letter ISO code"})
+		return "", false
+	}
+
+	ok, err := f.Currency.Supported(c.Request.Context(), code)
+	if err != nil {
+		log.Printf("[bindCurrencyQuery] %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "currency conversion unavailable"})
+		return "", false
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency " + code})
+		return "", false
+	}
+	return code, true
+}
+
+// convertResult converts every fare of a result to the requested currency. On failure the error
+// response has already been written.
+func (f *FlightHandler) convertResult(c *gin.Context, result *models.FlightSearchResult, code string) (*models.FlightSearchResult, bool) {
+	converted, err := f.Currency.ConvertResult(c.Request.Context(), result, code)
+	if err == nil {
+		return converted, true
+	}
+
+	log.Printf("[convertResult] %v", err)
+	if errors.Is(err, currency.ErrUnknownCurrency) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "search results use an unsupported currency"})
+	} else {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "currency conversion unavailable"})
+	}
+	return nil, false
+}
+
+// convertCalendar converts the minimum price of every calendar cell in place
+func (f *FlightHandler) convertCalendar(c *gin.Context, cal *search.Calendar, code string) bool {
+	if code == "" {
+		return true
+	}
+	// one table for every cell, so the rate source is asked once and all cells share its rates
+	rates, err := f.Currency.Rates(c.Request.Context())
+	if err != nil {
+		log.Printf("[convertCalendar] %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "currency conversion unavailable"})
+		return false
+	}
+	for i := range cal.Cells {
+		for j := range cal.Cells[i] {
+			cell := &cal.Cells[i][j]
+			if cell.MinPrice == nil {
+				continue
+			}
+			price, err := rates.Convert(*cell.MinPrice, cell.Currency, code)
+			if err != nil {
+				log.Printf("[convertCalendar] %v", err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "currency conversion unavailable"})
+				return false
+			}
+			cell.MinPrice, cell.Currency = &price, code
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RawSearchResults handles GET /api/admin/searches/:searchId/raw and returns one poll of an
+// upstream search exactly as the supplier sent it, without any result processing.
+// It is meant for debugging fare discrepancies and is only routed behind admin auth.
+func (f *FlightHandler) RawSearchResults(c *gin.Context) {
+	searchID := c.Param("searchId")
+
+	results, err := f.FlightApi.GetSearchResults(c.Request.Context(), searchID)
+	if err != nil {
+		log.Printf("[RawSearchResults] %s: %v", searchID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to get search results"})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -1,72 +1,321 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"stopover.backend/config"
+	"stopover.backend/internal/alerts"
+	"stopover.backend/internal/booking"
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/enrich"
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/places"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/search"
 	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/common"
+	"stopover.backend/pkg/money"
 
 	"github.com/gin-gonic/gin"
 )
 
 type FlightHandler struct {
 	FlightApi aviasales.FlightIntegrationAPI
+	Providers provider.Searcher
+	Sessions  *search.Store
+	Results   *search.ResultCache
+	Calendar  *search.CalendarService
+	Currency  *currency.Service
+	Tracker   booking.Tracker
+	Places    *places.Index
+	Enricher  *enrich.Enricher
+	Alerts    *alerts.Service
 	Config    *config.Config
 }
 
-func NewFlightHandler(flightApi aviasales.FlightIntegrationAPI, config *config.Config) *FlightHandler {
+func NewFlightHandler(flightApi aviasales.FlightIntegrationAPI, providers provider.Searcher, sessions *search.Store, results *search.ResultCache, calendar *search.CalendarService, rates *currency.Service, tracker booking.Tracker, airports *places.Index, enricher *enrich.Enricher, priceAlerts *alerts.Service, config *config.Config) *FlightHandler {
 	return &FlightHandler{
 		FlightApi: flightApi,
+		Providers: providers,
+		Sessions:  sessions,
+		Results:   results,
+		Calendar:  calendar,
+		Currency:  rates,
+		Tracker:   tracker,
+		Places:    airports,
+		Enricher:  enricher,
+		Alerts:    priceAlerts,
 		Config:    config,
 	}
 }
 
-// SearchFlightsAPI handles GET /api/flights with query params and proxies to Aviasales
+// SearchFlightsAPI handles GET /api/flights with query params and searches every registered provider.
+// The full result set is cached and a page of it is returned with facets; passing the returned
+// resultId pages through the cached set without searching again. Fares are shown in the currency
+// param when given and in the supplier's unified currency otherwise. The airlines filter keeps
+// itineraries whose carriers are all in the list, so a connection on any other airline excludes
+// one; arriveBefore applies to the local date each leg departs, so overnight arrivals are excluded.
 func (f *FlightHandler) SearchFlightsAPI(c *gin.Context) {
 	ctx := c.Request.Context()
 	ip := c.ClientIP()
 
-	origin := c.Query("origin")
-	destination := c.Query("destination")
-	departure := c.Query("departure") // YYYY-MM-DD
-	returnDate := c.Query("return")   // optional YYYY-MM-DD
-	adultsStr := c.DefaultQuery("adults", "1")
-	tripType := c.DefaultQuery("tripType", "one-way")
+	filter, order, err := bindFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if origin == "" || destination == "" || departure == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing required params: origin, destination, departure"})
+	page, pageSize, err := bindPageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	adults := 1
-	if n, err := strconv.Atoi(adultsStr); err == nil && n > 0 {
-		adults = n
+	currencyCode, ok := f.bindCurrencyQuery(c)
+	if !ok {
+		return
 	}
 
-	segments := []aviasales.Segment{
-		{Origin: origin, Destination: destination, Date: departure},
+	if resultID := c.Query("resultId"); resultID != "" {
+		results, ok := f.Results.Get(ctx, resultID)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "search results not found or expired"})
+			return
+		}
+		if results, ok = f.convertResult(c, results, currencyCode); !ok {
+			return
+		}
+		c.JSON(http.StatusOK, search.NewResultPage(resultID, results, filter, order, page, pageSize))
+		return
 	}
-	if tripType == "round-trip" && returnDate != "" {
-		segments = append(segments, aviasales.Segment{Origin: destination, Destination: origin, Date: returnDate})
+
+	params, err := bindSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	req := aviasales.FlightSearchRequest{
-		Marker:    f.Config.AviaSalesConfig.AviaSalesMarker,
-		Host:      f.Config.AviaSalesConfig.AviaSalesHost,
+	query := newFlightQuery(ip, params)
+	results, err := f.Providers.Search(ctx, query)
+	if err != nil {
+		log.Printf("Flight search error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to get search results"})
+		return
+	}
+
+	// results are cached in the supplier currency so any currency can be requested when paging
+	resultID := f.Results.Put(ctx, results)
+	f.trackSearch(c, resultID, query, results)
+	if results, ok = f.convertResult(c, results, currencyCode); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, search.NewResultPage(resultID, results, filter, order, page, pageSize))
+}
+
+// bindSearchQuery reads and validates the flight search params from the query string
+func bindSearchQuery(c *gin.Context) (models.FlightSearchParams, error) {
+	var params models.FlightSearchParams
+	if err := common.ValidateQuery(c, &params); err != nil {
+		return params, err
+	}
+	return params, normalizeSearchParams(&params)
+}
+
+// cabin names accepted by the API and their Aviasales trip class
+var cabinTripClass = map[string]string{
+	"economy":  "Y",
+	"business": "C",
+	"first":    "F",
+}
+
+// maxPassengers is the largest party a single booking can hold
+const maxPassengers = 9
+
+// normalizeSearchParams fills defaults left by json bodies and checks the passenger rules
+// that cannot be expressed as validation tags
+func normalizeSearchParams(params *models.FlightSearchParams) error {
+	params.Origin = strings.ToUpper(params.Origin)
+	params.Destination = strings.ToUpper(params.Destination)
+	if params.Adults == 0 {
+		params.Adults = 1
+	}
+	if params.Cabin == "" {
+		params.Cabin = "economy"
+	}
+	if params.Locale == "" {
+		params.Locale = "en"
+	}
+	if params.TripType == "" {
+		params.TripType = "one-way"
+	}
+
+	if err := checkPassengers(params.Adults, params.Children, params.Infants); err != nil {
+		return err
+	}
+	if params.TripType == "round-trip" && params.Return == "" {
+		return errors.New("missing required param for round-trip: return")
+	}
+	return nil
+}
+
+// checkPassengers enforces the party size and infant-per-adult rules
+func checkPassengers(adults, children, infants int) error {
+	if infants > adults {
+		return errors.New("each infant must travel with an adult: infants cannot exceed adults")
+	}
+	if total := adults + children + infants; total > maxPassengers {
+		return fmt.Errorf("at most %d passengers per search, got %d", maxPassengers, total)
+	}
+	return nil
+}
+
+// bindFilterQuery reads the result filters and sort order from the query string
+func bindFilterQuery(c *gin.Context) (search.Filter, search.SortOrder, error) {
+	var filter search.Filter
+
+	order, err := search.ParseSortOrder(c.Query("sort"))
+	if err != nil {
+		return filter, "", err
+	}
+
+	if v := c.Query("maxStops"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, "", fmt.Errorf("invalid maxStops %q", v)
+		}
+		filter.MaxStops = &n
+	}
+
+	filter.Airlines = splitCodes(c.Query("airlines"))
+	filter.Origins = splitCodes(c.Query("origins"))
+	filter.Destinations = splitCodes(c.Query("destinations"))
+
+	if v := c.Query("departAfter"); v != "" {
+		m, err := search.ParseClock(v)
+		if err != nil {
+			return filter, "", err
+		}
+		filter.DepartAfter = &m
+	}
+
+	if v := c.Query("arriveBefore"); v != "" {
+		m, err := search.ParseClock(v)
+		if err != nil {
+			return filter, "", err
+		}
+		filter.ArriveBefore = &m
+	}
+
+	if v := c.Query("maxDuration"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return filter, "", fmt.Errorf("invalid maxDuration %q: expected minutes", v)
+		}
+		filter.MaxDuration = n
+	}
+
+	if v := c.Query("minPrice"); v != "" {
+		amount, err := money.Parse(v)
+		if err != nil || amount < 0 {
+			return filter, "", fmt.Errorf("invalid minPrice %q", v)
+		}
+		filter.MinPrice = amount
+	}
+
+	if v := c.Query("maxPrice"); v != "" {
+		amount, err := money.Parse(v)
+		if err != nil || amount <= 0 {
+			return filter, "", fmt.Errorf("invalid maxPrice %q", v)
+		}
+		filter.MaxPrice = amount
+	}
+
+	for param, bound := range map[string]*int{"minLayover": &filter.MinLayover, "maxLayover": &filter.MaxLayover} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return filter, "", fmt.Errorf("invalid %s %q: expected minutes", param, v)
+			}
+			*bound = n
+		}
+	}
+
+	flags := map[string]*bool{
+		"noShortConnections": &filter.NoShort,
+		"noOvernight":        &filter.NoOvernight,
+		"noAirportChange":    &filter.NoAirportChange,
+		"noSelfTransfer":     &filter.NoSelfTransfer,
+		"noRedEye":           &filter.NoRedEye,
+	}
+	for param, flag := range flags {
+		if v := c.Query(param); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return filter, "", fmt.Errorf("invalid %s %q: expected true or false", param, v)
+			}
+			*flag = b
+		}
+	}
+
+	return filter, order, nil
+}
+
+// splitCodes reads a comma separated list of codes
+func splitCodes(v string) []string {
+	var codes []string
+	for _, code := range strings.Split(v, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// bindPageQuery reads the 1-based page number and page size from the query string
+func bindPageQuery(c *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		return 0, 0, fmt.Errorf("invalid page %q", c.Query("page"))
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(search.DefaultPageSize)))
+	if err != nil || pageSize <= 0 || pageSize > search.MaxPageSize {
+		return 0, 0, fmt.Errorf("invalid pageSize %q: expected 1-%d", c.Query("pageSize"), search.MaxPageSize)
+	}
+	return page, pageSize, nil
+}
+
+// newFlightQuery builds a provider-neutral query from normalized search params
+func newFlightQuery(ip string, params models.FlightSearchParams) models.FlightQuery {
+	segments := []models.QuerySegment{
+		{Origin: params.Origin, Destination: params.Destination, Date: params.Departure},
+	}
+	if params.TripType == "round-trip" && params.Return != "" {
+		segments = append(segments, models.QuerySegment{Origin: params.Destination, Destination: params.Origin, Date: params.Return})
+	}
+
+	return models.FlightQuery{
+		Segments:  segments,
+		Adults:    params.Adults,
+		Children:  params.Children,
+		Infants:   params.Infants,
+		TripClass: cabinTripClass[params.Cabin],
+		Locale:    params.Locale,
 		UserIP:    ip,
-		Locale:    "en",
-		TripClass: "Y",
-		Passengers: aviasales.PassengerInfo{
-			Adults:   adults,
-			Children: 0,
-			Infants:  0,
-		},
-		Segments: segments,
 	}
+}
+
+// newSearchRequest builds a signed Aviasales search request from normalized search params
+func (f *FlightHandler) newSearchRequest(ip string, params models.FlightSearchParams) aviasales.FlightSearchRequest {
+	req := provider.ToAviasalesRequest(newFlightQuery(ip, params))
+	req.Marker = f.Config.AviaSalesConfig.AviaSalesMarker
+	req.Host = f.Config.AviaSalesConfig.AviaSalesHost
 
 	// Signature generated inside client as well, but safe to set here
 	req.Signature = aviasales.GenerateSignature(
@@ -79,22 +328,7 @@ func (f *FlightHandler) SearchFlightsAPI(c *gin.Context) {
 		req.Passengers,
 		req.Segments,
 	)
-
-	initResp, err := f.FlightApi.InitSearch(ctx, req)
-	if err != nil {
-		log.Printf("InitSearch error: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to initialize flight search"})
-		return
-	}
-
-	results, err := f.FlightApi.GetSearchResultsWithPolling(ctx, initResp.SearchID, 10, 2*time.Second)
-	if err != nil {
-		log.Printf("Polling error: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to get search results"})
-		return
-	}
-
-	c.JSON(http.StatusOK, results)
+	return req
 }
 
 func (f *FlightHandler) SearchFlight(c *gin.Context) {
@@ -161,56 +395,3 @@ func (f *FlightHandler) SearchFlight(c *gin.Context) {
 	log.Printf("[Polling] Success. Found %d results.", len(results.Proposals))
 	c.JSON(http.StatusOK, results)
 }
-
-// AirportsAutocomplete proxies Aviasales airport autocomplete and returns a simplified payload
-func (f *FlightHandler) AirportsAutocomplete(c *gin.Context) {
-	q := c.Query("q")
-	if q == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing query parameter 'q'"})
-		return
-	}
-
-	// Aviasales autocomplete public endpoint (no auth required)
-	url := "https://autocomplete.travelpayouts.com/places2?types[]=airport&locale=en&term=" + q
-
-	resp, err := http.Get(url)
-	if err != nil {
-		log.Printf("autocomplete request failed: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch suggestions"})
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "upstream error", "status": resp.StatusCode})
-		return
-	}
-
-	var raw []map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "invalid upstream response"})
-		return
-	}
-
-	type item struct {
-		Code    string `json:"code"`
-		Name    string `json:"name"`
-		City    string `json:"city"`
-		Country string `json:"country"`
-	}
-
-	res := make([]item, 0, len(raw))
-	for _, r := range raw {
-		// Aviasales fields: code, name, city_name, country_name
-		code, _ := r["code"].(string)
-		name, _ := r["name"].(string)
-		city, _ := r["city_name"].(string)
-		country, _ := r["country_name"].(string)
-		if code == "" || name == "" {
-			continue
-		}
-		res = append(res, item{Code: code, Name: name, City: city, Country: country})
-	}
-
-	c.JSON(http.StatusOK, gin.H{"items": res})
-}
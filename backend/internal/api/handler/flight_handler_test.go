@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stopover.backend/config"
+	"stopover.backend/internal/api/handler"
+	"stopover.backend/internal/api/route"
+	"stopover.backend/internal/booking"
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/enrich"
+	"stopover.backend/internal/places"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/search"
+	"stopover.backend/internal/utils/cache"
+	"stopover.backend/internal/utils/middleware"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/aviasales/fake"
+	"stopover.backend/pkg/jwtutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newFakeRouter serves the api with the aviasales client pointed at a fake server
+func newFakeRouter(t *testing.T, opts fake.Options) http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	fs := httptest.NewServer(fake.New(opts))
+	t.Cleanup(fs.Close)
+
+	cfg := &config.Config{SecretKey: "test", AviaSalesConfig: fake.Config(fs.URL)}
+	cfg.AviaSalesConfig.RetryBaseDelay = time.Millisecond
+	client := aviasales.NewFlightIntegrationClient("tok", "marker", "stopover.test", cfg)
+
+	airports := places.NewIndex(places.DefaultAirports())
+	h := handler.NewFlightHandler(
+		client,
+		provider.NewAggregator(0, provider.NewAviasalesProvider(client, 10, time.Millisecond)),
+		search.NewStore(0),
+		search.NewResultCache(cache.NewMemoryStore(), 0),
+		search.NewCalendarService(client, cache.NewMemoryStore(), 4, time.Millisecond, 0),
+		currency.NewService(currency.NewStaticProvider(currency.DefaultRates())),
+		booking.NewLogTracker(),
+		airports,
+		enrich.NewEnricher(airports, enrich.DefaultAirlines(), enrich.DefaultAircraft(), ""),
+		nil,
+		cfg,
+	)
+	return route.SetupRouter(h, cfg, middleware.NewAuthRepo(nil), jwtutil.NewTokenService(*cfg))
+}
+
+func TestSearchFlightsAPIAgainstFakeServer(t *testing.T) {
+	router := newFakeRouter(t, fake.Options{Token: "tok", Polls: 3, ChunksPerPoll: 2, Gzip: true, FailPolls: 1})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/flights?origin=del&destination=cok&departure=2025-10-01&return=2025-10-05&tripType=round-trip&pageSize=3", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	var page search.ResultPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if want := len(fake.DefaultFixture().Offers); page.Pagination.Total != want {
+		t.Errorf("total = %d, want %d", page.Pagination.Total, want)
+	}
+	if len(page.Itineraries) != 3 {
+		t.Errorf("page holds %d itineraries, want 3", len(page.Itineraries))
+	}
+	for _, it := range page.Itineraries {
+		if len(it.Legs) != 2 {
+			t.Errorf("itinerary %s has %d legs, want outbound and return", it.Id, len(it.Legs))
+		}
+	}
+	if page.ResultID == "" {
+		t.Fatal("no result id")
+	}
+
+	// paging the cached result does not search again
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/flights?resultId="+page.ResultID+"&page=2&pageSize=3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("paging status = %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/search"
+	"stopover.backend/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+var iataCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// SearchMultiCity handles POST /api/flights/search for open-jaw and multi-city trips.
+// Every requested leg becomes a search segment; fares for the same combination of flights
+// are grouped under one itinerary. The result is cached like /api/flights, so the returned
+// result_id can be paged and filtered through GET /api/flights?resultId=.
+func (f *FlightHandler) SearchMultiCity(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req models.MultiCitySearchRequest
+	if err := common.ValidateRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query, err := newMultiCityQuery(c.ClientIP(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, order, err := bindFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, pageSize, err := bindPageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currencyCode, ok := f.bindCurrencyQuery(c)
+	if !ok {
+		return
+	}
+
+	results, err := f.Providers.Search(ctx, query)
+	if err != nil {
+		log.Printf("Multi-city search error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to get search results"})
+		return
+	}
+
+	resultID := f.Results.Put(ctx, results)
+	f.trackSearch(c, resultID, query, results)
+	if results, ok = f.convertResult(c, results, currencyCode); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, search.NewResultPage(resultID, results, filter, order, page, pageSize))
+}
+
+// newMultiCityQuery validates the legs and builds a provider-neutral query from them
+func newMultiCityQuery(ip string, req *models.MultiCitySearchRequest) (models.FlightQuery, error) {
+	if req.Adults == 0 {
+		req.Adults = 1
+	}
+	if req.Cabin == "" {
+		req.Cabin = "economy"
+	}
+	if req.Locale == "" {
+		req.Locale = "en"
+	}
+	if err := checkPassengers(req.Adults, req.Children, req.Infants); err != nil {
+		return models.FlightQuery{}, err
+	}
+
+	segments := make([]models.QuerySegment, 0, len(req.Legs))
+	var prev time.Time
+	for i, leg := range req.Legs {
+		origin := strings.ToUpper(leg.Origin)
+		destination := strings.ToUpper(leg.Destination)
+		if !iataCodePattern.MatchString(origin) || !iataCodePattern.MatchString(destination) {
+			return models.FlightQuery{}, fmt.Errorf("leg %d: origin and destination must be 3-letter IATA codes", i+1)
+		}
+		if origin == destination {
+			return models.FlightQuery{}, fmt.Errorf("leg %d: origin and destination must differ", i+1)
+		}
+
+		date, err := time.Parse("2006-01-02", leg.Date)
+		if err != nil {
+			return models.FlightQuery{}, fmt.Errorf("leg %d: invalid date %q", i+1, leg.Date)
+		}
+		if i > 0 && date.Before(prev) {
+			return models.FlightQuery{}, fmt.Errorf("leg %d: date %s is before the previous leg", i+1, leg.Date)
+		}
+		prev = date
+
+		segments = append(segments, models.QuerySegment{Origin: origin, Destination: destination, Date: leg.Date})
+	}
+
+	return models.FlightQuery{
+		Segments:  segments,
+		Adults:    req.Adults,
+		Children:  req.Children,
+		Infants:   req.Infants,
+		TripClass: cabinTripClass[req.Cabin],
+		Locale:    req.Locale,
+		UserIP:    ip,
+	}, nil
+}
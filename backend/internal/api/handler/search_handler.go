@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/search"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSearch handles POST /api/searches: starts an upstream search and returns a session id immediately
+func (f *FlightHandler) CreateSearch(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var params models.FlightSearchParams
+	if err := common.ValidateRequest(c, &params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := normalizeSearchParams(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req := f.newSearchRequest(c.ClientIP(), params)
+
+	initResp, err := f.FlightApi.InitSearch(ctx, req)
+	if err != nil {
+		log.Printf("InitSearch error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to initialize flight search"})
+		return
+	}
+
+	session := f.startSession(initResp.SearchID, req)
+
+	c.JSON(http.StatusAccepted, models.CreateSearchResponse{
+		Id:        session.ID,
+		SearchId:  session.SearchID,
+		ExpiresAt: session.ExpiresAt.Unix(),
+	})
+}
+
+// GetSearch handles GET /api/searches/:id and returns one page of the itineraries accumulated
+// so far, narrowed and ordered by the same filter params as /api/flights
+func (f *FlightHandler) GetSearch(c *gin.Context) {
+	filter, order, err := bindFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, pageSize, err := bindPageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currencyCode, ok := f.bindCurrencyQuery(c)
+	if !ok {
+		return
+	}
+
+	session, ok := f.Sessions.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "search not found or expired"})
+		return
+	}
+
+	snap := session.Snapshot()
+	f.Enricher.Itineraries(snap.Itineraries)
+	converted, ok := f.convertResult(c, &models.FlightSearchResult{
+		Itineraries: snap.Itineraries,
+		Currency:    snap.Currency,
+	}, currencyCode)
+	if !ok {
+		return
+	}
+	result := search.NewResultPage(snap.ID, converted, filter, order, page, pageSize)
+	snap.Currency = converted.Currency
+	snap.Itineraries = result.Itineraries
+	snap.Pagination = &result.Pagination
+	snap.Facets = &result.Facets
+
+	c.JSON(http.StatusOK, snap)
+}
+
+// startSession registers a session and polls upstream for it in the background.
+// The poll outlives the HTTP request, so it runs on its own context bounded by the session expiry.
+func (f *FlightHandler) startSession(searchID string, req aviasales.FlightSearchRequest) *search.Session {
+	session := f.Sessions.Create(searchID, req)
+
+	go func() {
+		ctx, cancel := context.WithDeadline(context.Background(), session.ExpiresAt)
+		defer cancel()
+		session.Poll(ctx, f.FlightApi, search.DefaultPollAttempts, search.DefaultPollInterval)
+	}()
+
+	return session
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"stopover.backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamFlights handles GET /api/flights/stream and pushes itineraries to the client as Server-Sent Events.
+// It attaches to an existing session when searchId is given, otherwise it starts a new search from the
+// same query params as /api/flights. Every new batch is sent as an "itineraries" event, with itineraries
+// that gained fares sent again under the same id, and the stream ends with a single "done" event once
+// the session completes.
+func (f *FlightHandler) StreamFlights(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	currencyCode, ok := f.bindCurrencyQuery(c)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Query("searchId")
+	if sessionID == "" {
+		params, err := bindSearchQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		req := f.newSearchRequest(c.ClientIP(), params)
+		initResp, err := f.FlightApi.InitSearch(ctx, req)
+		if err != nil {
+			log.Printf("InitSearch error: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to initialize flight search"})
+			return
+		}
+		sessionID = f.startSession(initResp.SearchID, req).ID
+	}
+
+	session, ok := f.Sessions.Get(sessionID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "search not found or expired"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("search", gin.H{"id": session.ID, "search_id": session.SearchID})
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	version := 0
+	c.Stream(func(w io.Writer) bool {
+		batch, complete, updated := session.Since(version)
+		if len(batch.Itineraries) > 0 {
+			version = batch.Version
+			f.Enricher.Itineraries(batch.Itineraries)
+			converted, err := f.Currency.ConvertResult(ctx, &models.FlightSearchResult{
+				Itineraries: batch.Itineraries,
+				Currency:    batch.Currency,
+			}, currencyCode)
+			if err != nil {
+				log.Printf("[StreamFlights] currency conversion failed: %v", err)
+				c.SSEvent("done", gin.H{"total": batch.Total, "error": "currency conversion unavailable"})
+				return false
+			}
+			batch.Itineraries, batch.Currency = converted.Itineraries, converted.Currency
+			c.SSEvent("itineraries", batch)
+			return true
+		}
+
+		if complete {
+			done := gin.H{"total": batch.Total}
+			if err := session.Err(); err != nil {
+				done["error"] = err.Error()
+			}
+			c.SSEvent("done", done)
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"total": batch.Total})
+		case <-updated:
+		}
+		return true
+	})
+}
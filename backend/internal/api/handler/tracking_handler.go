@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"stopover.backend/internal/booking"
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trackingTimeout bounds a search recording that runs after the response is sent
+const trackingTimeout = 5 * time.Second
+
+// trackSearch records a search that returned results without delaying the response
+func (f *FlightHandler) trackSearch(c *gin.Context, resultID string, query models.FlightQuery, results *models.FlightSearchResult) {
+	if len(results.Itineraries) == 0 || len(query.Segments) == 0 {
+		return
+	}
+	session := newSearchSession(resultID, query, results)
+	if user := common.GetUserFromContext(c.Request.Context()); user != nil {
+		session.UserId = user.UserId
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, trackingTimeout)
+		defer cancel()
+		if err := f.Tracker.RecordSearch(ctx, session); err != nil {
+			log.Printf("[trackSearch] failed to record search %s: %v", resultID, err)
+		}
+	}()
+}
+
+func newSearchSession(resultID string, query models.FlightQuery, results *models.FlightSearchResult) models.SearchSession {
+	first := query.Segments[0]
+	session := models.SearchSession{
+		ResultId:      resultID,
+		Origin:        first.Origin,
+		Destination:   first.Destination,
+		DepartureDate: first.Date,
+		Segments:      len(query.Segments),
+		TripClass:     query.TripClass,
+		Adults:        query.Adults,
+		Children:      query.Children,
+		Infants:       query.Infants,
+		Itineraries:   len(results.Itineraries),
+		Currency:      results.Currency,
+		UserIP:        query.UserIP,
+	}
+	// a round trip is two segments that mirror each other
+	if len(query.Segments) == 2 && query.Segments[1].Origin == first.Destination && query.Segments[1].Destination == first.Origin {
+		session.ReturnDate = query.Segments[1].Date
+	}
+
+	for _, it := range results.Itineraries {
+		best, ok := it.BestFare()
+		if !ok {
+			continue
+		}
+		// the search id goes with the cheapest fare, which may come from another provider's search
+		if session.MinPrice == 0 || best.UnifiedPrice < session.MinPrice {
+			session.MinPrice, session.SearchId = best.UnifiedPrice, best.SearchID
+		}
+	}
+	return session
+}
+
+// ClickReport handles GET /api/admin/reports/clicks and aggregates click-outs by day, route and
+// gate. from and to are inclusive yyyy-mm-dd dates; origin, destination and gate narrow the report.
+func (f *FlightHandler) ClickReport(c *gin.Context) {
+	var req models.ClickReportRequest
+	if err := common.ValidateQuery(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := f.Tracker.ClickReport(c.Request.Context(), req)
+	switch {
+	case errors.Is(err, booking.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, booking.ErrReportUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("[ClickReport] %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build click report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"testing"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+func TestNewSearchSession(t *testing.T) {
+	segment := func(origin, destination, date string) models.QuerySegment {
+		return models.QuerySegment{Origin: origin, Destination: destination, Date: date}
+	}
+	fare := func(units int64, searchID string) models.Fare {
+		return models.Fare{UnifiedPrice: money.FromUnits(units, 0), SearchID: searchID}
+	}
+	results := &models.FlightSearchResult{
+		Currency: "INR",
+		Itineraries: []models.Itinerary{
+			{Fares: []models.Fare{{SearchID: "unpriced"}}},
+			{Fares: []models.Fare{fare(5400, "first"), fare(5200, "first")}},
+			{Fares: []models.Fare{fare(4100, "second")}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		segments   []models.QuerySegment
+		wantReturn string
+	}{
+		{name: "one way", segments: []models.QuerySegment{segment("DEL", "BOM", "2025-10-01")}},
+		{name: "round trip", segments: []models.QuerySegment{segment("DEL", "BOM", "2025-10-01"), segment("BOM", "DEL", "2025-10-08")}, wantReturn: "2025-10-08"},
+		{name: "open jaw", segments: []models.QuerySegment{segment("DEL", "BOM", "2025-10-01"), segment("GOI", "DEL", "2025-10-08")}},
+		{name: "three segments", segments: []models.QuerySegment{segment("DEL", "BOM", "2025-10-01"), segment("BOM", "DEL", "2025-10-08"), segment("DEL", "BOM", "2025-10-12")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := models.FlightQuery{Segments: tt.segments, Adults: 2, Children: 1, TripClass: "Y", UserIP: "10.0.0.1"}
+			got := newSearchSession("result-1", query, results)
+
+			want := models.SearchSession{
+				ResultId:      "result-1",
+				SearchId:      "second",
+				Origin:        "DEL",
+				Destination:   "BOM",
+				DepartureDate: "2025-10-01",
+				ReturnDate:    tt.wantReturn,
+				Segments:      len(tt.segments),
+				TripClass:     "Y",
+				Adults:        2,
+				Children:      1,
+				Itineraries:   3,
+				MinPrice:      money.FromUnits(4100, 0),
+				Currency:      "INR",
+				UserIP:        "10.0.0.1",
+			}
+			if got != want {
+				t.Errorf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewSearchSessionWithoutPrices(t *testing.T) {
+	query := models.FlightQuery{Segments: []models.QuerySegment{{Origin: "DEL", Destination: "BOM", Date: "2025-10-01"}}}
+	results := &models.FlightSearchResult{Itineraries: []models.Itinerary{{Fares: []models.Fare{{SearchID: "unpriced"}}}}}
+
+	got := newSearchSession("result-1", query, results)
+	if got.MinPrice != 0 || got.SearchId != "" || got.Itineraries != 1 {
+		t.Fatalf("got %+v, want no price or search id", got)
+	}
+}
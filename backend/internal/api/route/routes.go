@@ -3,12 +3,15 @@ package route
 import (
 	"stopover.backend/config"
 	"stopover.backend/internal/api/handler"
+	"stopover.backend/internal/utils/middleware"
+	"stopover.backend/pkg/common"
+	"stopover.backend/pkg/jwtutil"
 
 	"github.com/gin-gonic/gin"
 )
 
 // func SetupRouter(handler *handler.Handler, cfg config.Config, authMiddleware gin.HandlerFunc) *gin.Engine {
-func SetupRouter(fhandler *handler.FlightHandler, cfg *config.Config) *gin.Engine {
+func SetupRouter(fhandler *handler.FlightHandler, cfg *config.Config, authRepo middleware.AuthRepo, tokenRepo jwtutil.TokenRepo) *gin.Engine {
 	router := gin.Default()
 
 	// Minimal CORS for frontend dev
@@ -27,7 +30,32 @@ func SetupRouter(fhandler *handler.FlightHandler, cfg *config.Config) *gin.Engin
 	api := router.Group("/api")
 	{
 		api.GET("/airports/autocomplete", fhandler.AirportsAutocomplete)
-		api.GET("/flights", fhandler.SearchFlightsAPI)
+		api.GET("/airports/nearby", fhandler.NearbyAirports)
+		api.GET("/flights", authRepo.OptionalAuthUser(tokenRepo), fhandler.SearchFlightsAPI)
+		api.GET("/flights/stream", fhandler.StreamFlights)
+		api.POST("/flights/search", authRepo.OptionalAuthUser(tokenRepo), fhandler.SearchMultiCity)
+		api.GET("/flights/calendar", fhandler.FlightCalendar)
+		api.GET("/flights/:searchId/book", authRepo.OptionalAuthUser(tokenRepo), fhandler.BookFlight)
+		api.POST("/searches", fhandler.CreateSearch)
+		api.GET("/searches/:id", fhandler.GetSearch)
+	}
+
+	// price alerts of the signed-in user
+	alerts := router.Group("/api/alerts", authRepo.AuthUser(tokenRepo))
+	{
+		alerts.GET("", fhandler.ListPriceAlerts)
+		alerts.POST("", fhandler.CreatePriceAlert)
+		alerts.GET("/:id", fhandler.GetPriceAlert)
+		alerts.PUT("/:id", fhandler.UpdatePriceAlert)
+		alerts.DELETE("/:id", fhandler.DeletePriceAlert)
+		alerts.GET("/:id/history", fhandler.PriceAlertHistory)
+	}
+
+	// admin-only debugging and reporting endpoints
+	admin := router.Group("/api/admin", authRepo.AuthUser(tokenRepo), authRepo.RequireRole(common.Admin))
+	{
+		admin.GET("/searches/:searchId/raw", fhandler.RawSearchResults)
+		admin.GET("/reports/clicks", fhandler.ClickReport)
 	}
 
 	// legacy flight group (kept as-is)
@@ -10,15 +10,25 @@ import (
 	"time"
 
 	"stopover.backend/config"
+	"stopover.backend/internal/alerts"
 	"stopover.backend/internal/api/handler"
 	"stopover.backend/internal/api/route"
-
-	// "stopover.backend/internal/repository"
+	"stopover.backend/internal/booking"
+	"stopover.backend/internal/currency"
+	"stopover.backend/internal/enrich"
+	"stopover.backend/internal/places"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/repository"
+	"stopover.backend/internal/search"
+	"stopover.backend/internal/utils/cache"
+	"stopover.backend/internal/utils/middleware"
 	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/jwtutil"
 
 	// _ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/redis/go-redis/v9"
 )
 
 func StartServer() {
@@ -28,20 +38,76 @@ func StartServer() {
 	rootCtx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
 
-	// // init postgres db
-	// dbConn, err := repository.NewPostgres(context.Background(), cfg)
-	// if err != nil {
-	// 	log.Fatal(err.Error())
-	// }
+	// postgres is optional; without it searches and click-outs are only logged and price alerts are off
+	tracker := booking.NewLogTracker()
+	var alertRepo repository.AlertRepository
+	if cfg.DBHost != "" {
+		dbConn, err := repository.NewPostgres(rootCtx, cfg)
+		if err != nil {
+			log.Printf("[StartServer] database unavailable, tracking to log only: %v", err)
+		} else {
+			defer dbConn.Close()
+			tracker = booking.NewDBTracker(repository.NewTrackingRepository(dbConn))
+			alertRepo = repository.NewAlertRepository(dbConn)
+		}
+	}
 
 	fClient := aviasales.NewFlightIntegrationClient(cfg.AviaSalesConfig.AviaSalesToken,
 		cfg.AviaSalesConfig.AviaSalesMarker,
 		cfg.AviaSalesConfig.AviaSalesHost, &cfg)
 
-	fHnldr := handler.NewFlightHandler(fClient, &cfg)
+	sessions := search.NewStore(cfg.SearchSessionTTL)
+	sessions.StartJanitor(rootCtx, time.Minute)
+
+	// register flight suppliers; each one is searched concurrently per request
+	providers := provider.NewAggregator(provider.DefaultProviderTimeout,
+		provider.NewAviasalesProvider(fClient, search.DefaultPollAttempts, search.DefaultPollInterval),
+	)
+
+	// shared cache infrastructure; every consumer gets its own namespace and falls back to memory without redis
+	var rdb *redis.Client
+	if cfg.RedisHostPort != "" {
+		client, err := cache.NewRedisClient(rootCtx, cfg)
+		if err != nil {
+			log.Printf("[StartServer] redis unavailable, using in-memory caches: %v", err)
+		} else {
+			rdb = client
+		}
+	}
+	// results are enriched before they are cached so every reader shares the enriched copy
+	airports := places.NewIndex(loadAirports(cfg.PlacesFile))
+	enricher := enrich.NewEnricher(airports, enrich.DefaultAirlines(), enrich.DefaultAircraft(), cfg.AirlineLogoURL)
+
+	searchCache := cache.NewSearchResultCache(cache.NewStore(rdb, "search"))
+	searcher := search.NewCachedSearcher(enrich.NewSearcher(providers, enricher), searchCache, cfg.SearchCacheTTL)
+
+	results := search.NewResultCache(cache.NewStore(rdb, "results"), search.DefaultResultTTL)
+
+	calendar := search.NewCalendarService(fClient, cache.NewStore(rdb, "calendar"), search.DefaultCalendarConcurrency,
+		search.DefaultPollInterval, search.DefaultCalendarCellTTL)
+
+	rates := currency.NewService(newRateProvider(cfg.CurrencyConfig, cache.NewStore(rdb, "fx")))
+
+	notifier := newAlertNotifier(cfg.AlertConfig)
+	priceAlerts := alerts.NewService(alertRepo, notifier, rates)
+	if alertRepo != nil {
+		monitor := alerts.NewMonitor(alertRepo, fClient, rates, notifier, alerts.MonitorConfig{
+			CheckInterval:     cfg.AlertConfig.CheckInterval,
+			PollInterval:      cfg.AlertConfig.PollInterval,
+			SearchesPerMinute: cfg.AlertConfig.SearchesPerMinute,
+		})
+		monitor.Start(rootCtx)
+	}
+
+	fHnldr := handler.NewFlightHandler(fClient, searcher, sessions, results, calendar, rates, tracker,
+		airports, enricher, priceAlerts, &cfg)
 
 	// Set up routes
-	router := route.SetupRouter(fHnldr, &cfg)
+	// the auth middleware only needs the token service until the user repository is wired up
+	authRepo := middleware.NewAuthRepo(nil)
+	tokenRepo := jwtutil.NewTokenService(cfg)
+
+	router := route.SetupRouter(fHnldr, &cfg, authRepo, tokenRepo)
 	srv := &http.Server{
 		Addr:    ":8084",
 		Handler: router.Handler(),
@@ -53,6 +119,51 @@ func StartServer() {
 	<-rootCtx.Done()
 }
 
+// newRateProvider picks the exchange rate source from config, falling back to the embedded rates
+func newRateProvider(cfg config.CurrencyConfig, store cache.Store) currency.RateProvider {
+	switch cfg.RatesSource {
+	case "file":
+		p, err := currency.NewFileProvider(cfg.RatesFile)
+		if err == nil {
+			return p
+		}
+		log.Printf("[newRateProvider] %v, using embedded rates", err)
+	case "http":
+		return currency.NewCachedProvider(currency.NewHTTPProvider(cfg.RatesURL), store, cfg.RatesTTL)
+	}
+	return currency.NewStaticProvider(currency.DefaultRates())
+}
+
+// newAlertNotifier registers the alert channels; email is only offered with an SMTP server configured
+func newAlertNotifier(cfg config.AlertConfig) *alerts.Router {
+	router := alerts.NewRouter().
+		Register(alerts.ChannelLog, alerts.NewLogNotifier()).
+		Register(alerts.ChannelWebhook, alerts.NewWebhookNotifier())
+	if cfg.SMTPHost != "" {
+		port, from := cfg.SMTPPort, cfg.SMTPFrom
+		if port == "" {
+			port = "25"
+		}
+		if from == "" {
+			from = "alerts@" + cfg.SMTPHost
+		}
+		router.Register(alerts.ChannelEmail, alerts.NewSMTPNotifier(cfg.SMTPHost, port, cfg.SMTPUser, cfg.SMTPPassword, from))
+	}
+	return router
+}
+
+// loadAirports reads the airport reference data from file, falling back to the embedded dataset
+func loadAirports(file string) []places.Airport {
+	if file != "" {
+		airports, err := places.LoadFile(file)
+		if err == nil {
+			return airports
+		}
+		log.Printf("[loadAirports] %v, using embedded airports", err)
+	}
+	return places.DefaultAirports()
+}
+
 func initGracefulShutdown(cancelFunc context.CancelFunc, dbConn *pgxpool.Pool, srv *http.Server) {
 
 	// Wait for interrupt signal to gracefully shutdown the server with
@@ -0,0 +1,60 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/repository"
+)
+
+const (
+	// DefaultReportDays is the range of a click report that names no dates
+	DefaultReportDays = 30
+	// MaxReportDays bounds a single report query
+	MaxReportDays = 366
+)
+
+type dbTracker struct {
+	repo repository.TrackingRepository
+}
+
+// NewDBTracker persists searches and clicks and reports on them
+func NewDBTracker(repo repository.TrackingRepository) Tracker {
+	return &dbTracker{repo: repo}
+}
+
+func (t *dbTracker) RecordSearch(ctx context.Context, session models.SearchSession) error {
+	return t.repo.CreateSearchSession(ctx, session)
+}
+
+func (t *dbTracker) RecordClick(ctx context.Context, click models.ClickOut) error {
+	return t.repo.CreateClickOut(ctx, click)
+}
+
+// ClickReport fills in the default range, ending today in UTC, before querying
+func (t *dbTracker) ClickReport(ctx context.Context, req models.ClickReportRequest) (*models.ClickReportResponse, error) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.To != "" {
+		to, _ = time.Parse(time.DateOnly, req.To)
+	}
+	from := to.AddDate(0, 0, 1-DefaultReportDays)
+	if req.From != "" {
+		from, _ = time.Parse(time.DateOnly, req.From)
+	}
+	if from.After(to) || to.Sub(from) >= MaxReportDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	req.From, req.To = from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	rows, err := t.repo.GetClickReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ClickReportResponse{From: req.From, To: req.To, Rows: rows}
+	for _, r := range rows {
+		response.Total += r.Clicks
+	}
+	return response, nil
+}
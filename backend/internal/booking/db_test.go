@@ -0,0 +1,67 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/repository"
+)
+
+// reportRepo returns canned rows and keeps the request it was asked for
+type reportRepo struct {
+	repository.TrackingRepository
+	rows []*models.ClickReportRow
+	req  *models.ClickReportRequest
+}
+
+func (r *reportRepo) GetClickReport(ctx context.Context, req models.ClickReportRequest) ([]*models.ClickReportRow, error) {
+	r.req = &req
+	return r.rows, nil
+}
+
+func TestClickReport(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := func(t time.Time) string { return t.Format(time.DateOnly) }
+
+	tests := []struct {
+		name             string
+		req              models.ClickReportRequest
+		wantFrom, wantTo string
+		wantErr          error
+	}{
+		{name: "default 30 days to today", wantFrom: date(today.AddDate(0, 0, -29)), wantTo: date(today)},
+		{name: "30 days to the given end", req: models.ClickReportRequest{To: "2025-03-31"}, wantFrom: "2025-03-02", wantTo: "2025-03-31"},
+		{name: "from the given start to today", req: models.ClickReportRequest{From: date(today.AddDate(0, 0, -3))}, wantFrom: date(today.AddDate(0, 0, -3)), wantTo: date(today)},
+		{name: "single day", req: models.ClickReportRequest{From: "2025-03-01", To: "2025-03-01"}, wantFrom: "2025-03-01", wantTo: "2025-03-01"},
+		{name: "longest range", req: models.ClickReportRequest{From: "2025-01-01", To: "2026-01-01"}, wantFrom: "2025-01-01", wantTo: "2026-01-01"},
+		{name: "too long", req: models.ClickReportRequest{From: "2024-01-01", To: "2025-01-01"}, wantErr: ErrInvalidRange},
+		{name: "inverted", req: models.ClickReportRequest{From: "2025-03-02", To: "2025-03-01"}, wantErr: ErrInvalidRange},
+		{name: "start after the default end", req: models.ClickReportRequest{From: date(today.AddDate(0, 0, 1))}, wantErr: ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &reportRepo{rows: []*models.ClickReportRow{{Clicks: 3}, {Clicks: 4}}}
+			tt.req.Gate = "gate"
+
+			got, err := NewDBTracker(repo).ClickReport(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || repo.req != nil {
+					t.Fatalf("got %v and queried %v, want %v without a query", err, repo.req, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.req.From != tt.wantFrom || repo.req.To != tt.wantTo || repo.req.Gate != "gate" {
+				t.Fatalf("queried %+v, want %s to %s for the gate", repo.req, tt.wantFrom, tt.wantTo)
+			}
+			if got.From != tt.wantFrom || got.To != tt.wantTo || got.Total != 7 || len(got.Rows) != 2 {
+				t.Fatalf("got %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"stopover.backend/internal/models"
+)
+
+var (
+	// ErrReportUnavailable is returned by trackers that keep no history to report on
+	ErrReportUnavailable = errors.New("booking: click reporting needs a database")
+	// ErrInvalidRange is returned for report ranges that are inverted or too long
+	ErrInvalidRange = errors.New("booking: invalid report date range")
+)
+
+// Tracker records searches and click-outs to agencies so affiliate revenue can be traced back to
+// the search that produced it. Recording must never block a search or a booking, so callers log
+// a failed call and carry on.
+type Tracker interface {
+	RecordSearch(ctx context.Context, session models.SearchSession) error
+	RecordClick(ctx context.Context, click models.ClickOut) error
+	ClickReport(ctx context.Context, req models.ClickReportRequest) (*models.ClickReportResponse, error)
+}
+
+type logTracker struct{}
+
+// NewLogTracker writes every search and click to the application log; used when no database is configured
+func NewLogTracker() Tracker {
+	return logTracker{}
+}
+
+func (logTracker) RecordSearch(ctx context.Context, s models.SearchSession) error {
+	log.Printf("[Search] result=%s search=%s route=%s-%s date=%s itineraries=%d user=%d", s.ResultId, s.SearchId,
+		s.Origin, s.Destination, s.DepartureDate, s.Itineraries, s.UserId)
+	return nil
+}
+
+func (logTracker) RecordClick(ctx context.Context, c models.ClickOut) error {
+	log.Printf("[Click] search=%s term=%s gate=%s click_id=%s price=%s %s user=%d ip=%s", c.SearchId, c.Term,
+		c.Gate, c.AgencyClickId, c.Price, c.Currency, c.UserId, c.UserIP)
+	return nil
+}
+
+func (logTracker) ClickReport(ctx context.Context, req models.ClickReportRequest) (*models.ClickReportResponse, error) {
+	return nil, ErrReportUnavailable
+}
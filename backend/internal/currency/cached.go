@@ -0,0 +1,67 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"stopover.backend/internal/utils/cache"
+)
+
+const DefaultRatesTTL = time.Hour
+
+const ratesCacheKey = "rates"
+
+// cachedTable is the cache form of a RateTable; rates are kept as exact fractions
+type cachedTable struct {
+	Base  string            `json:"base"`
+	AsOf  time.Time         `json:"as_of"`
+	Rates map[string]string `json:"rates"`
+}
+
+// CachedProvider keeps the rates of a slower source in a cache store, e.g. redis, so every
+// instance shares one fetch per ttl
+type CachedProvider struct {
+	next  RateProvider
+	cache *cache.Typed[cachedTable]
+	ttl   time.Duration
+}
+
+func NewCachedProvider(next RateProvider, store cache.Store, ttl time.Duration) *CachedProvider {
+	if ttl <= 0 {
+		ttl = DefaultRatesTTL
+	}
+	return &CachedProvider{
+		next:  next,
+		cache: cache.NewTyped[cachedTable](store, cache.JSONCodec),
+		ttl:   ttl,
+	}
+}
+
+func (p *CachedProvider) Rates(ctx context.Context) (*RateTable, error) {
+	ct, err := p.cache.GetOrLoad(ctx, ratesCacheKey, p.ttl, func(ctx context.Context) (cachedTable, error) {
+		t, err := p.next.Rates(ctx)
+		if err != nil {
+			return cachedTable{}, err
+		}
+		ct := cachedTable{Base: t.Base, AsOf: t.AsOf, Rates: make(map[string]string, len(t.Rates))}
+		for code, r := range t.Rates {
+			ct.Rates[code] = r.RatString()
+		}
+		return ct, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &RateTable{Base: ct.Base, AsOf: ct.AsOf, Rates: make(map[string]*big.Rat, len(ct.Rates))}
+	for code, s := range ct.Rates {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("cached rate %q for %s is invalid", s, code)
+		}
+		t.Rates[code] = r
+	}
+	return t, nil
+}
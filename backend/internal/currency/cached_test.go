@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"stopover.backend/internal/utils/cache"
+)
+
+type countingProvider struct {
+	table *RateTable
+	calls int
+}
+
+func (p *countingProvider) Rates(ctx context.Context) (*RateTable, error) {
+	p.calls++
+	return p.table, nil
+}
+
+func TestCachedProviderKeepsExactRates(t *testing.T) {
+	ctx := context.Background()
+	next := &countingProvider{table: &RateTable{Base: "USD", Rates: map[string]*big.Rat{"USD": rat("1"), "EUR": rat("1/3"), "INR": rat("85.7483")}}}
+	p := NewCachedProvider(next, cache.NewMemoryStore(), 0)
+
+	for i := 0; i < 2; i++ {
+		got, err := p.Rates(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for code, want := range next.table.Rates {
+			if got.Rates[code].Cmp(want) != 0 {
+				t.Fatalf("call %d: %s = %s, want %s", i, code, got.Rates[code].RatString(), want.RatString())
+			}
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("source called %d times, want once", next.calls)
+	}
+}
+
+func TestCachedProviderRejectsInvalidCachedRate(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore()
+	bad := cachedTable{Base: "USD", Rates: map[string]string{"EUR": "0.9x"}}
+	if err := cache.NewTyped[cachedTable](store, cache.JSONCodec).Set(ctx, ratesCacheKey, bad, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewCachedProvider(&countingProvider{}, store, 0)
+	if _, err := p.Rates(ctx); err == nil {
+		t.Fatal("expected an error for an invalid cached rate")
+	}
+}
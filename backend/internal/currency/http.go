@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPProvider fetches a rate document from a URL on every call; wrap it in a CachedProvider
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Rates(ctx context.Context) (*RateTable, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("fetch rates: HTTP %d: %s", resp.StatusCode, body)
+	}
+	return ParseRates(io.LimitReader(resp.Body, 1<<20))
+}
+
+// NewStubHandler serves a rate document in the format HTTPProvider reads, for local development.
+// The embedded default rates are served when doc is nil.
+func NewStubHandler(doc []byte) http.Handler {
+	if doc == nil {
+		doc = defaultRates
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+}
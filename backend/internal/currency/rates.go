@@ -0,0 +1,126 @@
+package currency
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrUnknownCurrency is returned when a currency has no exchange rate
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// RateTable holds exchange rates as units of each currency per one unit of Base.
+// Rates are exact rationals so conversions never pick up float64 drift.
+type RateTable struct {
+	Base  string
+	AsOf  time.Time
+	Rates map[string]*big.Rat
+}
+
+// Rate returns how many units of to one unit of from is worth
+func (t *RateTable) Rate(from, to string) (*big.Rat, error) {
+	f, ok := t.Rates[strings.ToUpper(from)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	r, ok := t.Rates[strings.ToUpper(to)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+	return new(big.Rat).Quo(r, f), nil
+}
+
+// RateProvider is a source of exchange rates
+type RateProvider interface {
+	Rates(ctx context.Context) (*RateTable, error)
+}
+
+// rateDocument is the wire format of rate files, the HTTP source and the stub:
+// {"base":"USD","date":"2025-01-02","rates":{"EUR":0.9612}}
+type rateDocument struct {
+	Base  string                 `json:"base"`
+	Date  string                 `json:"date"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// ParseRates reads a rate document. Numbers are parsed from their decimal text, not via float64.
+func ParseRates(r io.Reader) (*RateTable, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var doc rateDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode rates: %w", err)
+	}
+	if doc.Base == "" || len(doc.Rates) == 0 {
+		return nil, errors.New("decode rates: base and rates are required")
+	}
+
+	t := &RateTable{
+		Base:  strings.ToUpper(doc.Base),
+		Rates: make(map[string]*big.Rat, len(doc.Rates)+1),
+	}
+	if doc.Date != "" {
+		if d, err := time.Parse("2006-01-02", doc.Date); err == nil {
+			t.AsOf = d
+		}
+	}
+	for code, n := range doc.Rates {
+		r, ok := new(big.Rat).SetString(n.String())
+		if !ok || r.Sign() <= 0 {
+			return nil, fmt.Errorf("decode rates: invalid rate %q for %s", n, code)
+		}
+		t.Rates[strings.ToUpper(code)] = r
+	}
+	t.Rates[t.Base] = big.NewRat(1, 1)
+	return t, nil
+}
+
+//go:embed rates.json
+var defaultRates []byte
+
+// StaticProvider always returns the same table
+type StaticProvider struct {
+	table *RateTable
+}
+
+func NewStaticProvider(table *RateTable) *StaticProvider {
+	return &StaticProvider{table: table}
+}
+
+// DefaultRates returns the snapshot of rates embedded in the binary. It is good enough for
+// development and as a last resort, not for pricing.
+func DefaultRates() *RateTable {
+	t, err := ParseRates(bytes.NewReader(defaultRates))
+	if err != nil {
+		panic(fmt.Errorf("embedded rates: %w", err))
+	}
+	return t
+}
+
+// NewFileProvider loads a rate document from disk once
+func NewFileProvider(path string) (*StaticProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t, err := ParseRates(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewStaticProvider(t), nil
+}
+
+func (p *StaticProvider) Rates(ctx context.Context) (*RateTable, error) {
+	return p.table, nil
+}
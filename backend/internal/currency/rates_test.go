@@ -0,0 +1,81 @@
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("bad rat " + s)
+	}
+	return r
+}
+
+func TestParseRates(t *testing.T) {
+	doc := `{"base": "usd", "date": "2025-01-02", "rates": {"eur": 0.1234567890123456789, "INR": 85.7483}}`
+	table, err := ParseRates(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if table.Base != "USD" || !table.AsOf.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("base %q as of %s", table.Base, table.AsOf)
+	}
+	// a float64 would keep only about 17 significant digits of the EUR rate
+	want := map[string]string{"USD": "1", "EUR": "0.1234567890123456789", "INR": "85.7483"}
+	if len(table.Rates) != len(want) {
+		t.Fatalf("rates %v, want %v", table.Rates, want)
+	}
+	for code, s := range want {
+		if r, ok := table.Rates[code]; !ok || r.Cmp(rat(s)) != 0 {
+			t.Errorf("%s = %v, want %s", code, r, s)
+		}
+	}
+}
+
+func TestParseRatesRejects(t *testing.T) {
+	tests := map[string]string{
+		"zero rate":     `{"base": "USD", "rates": {"EUR": 0}}`,
+		"negative rate": `{"base": "USD", "rates": {"EUR": -0.96}}`,
+		"no base":       `{"rates": {"EUR": 0.96}}`,
+		"no rates":      `{"base": "USD", "rates": {}}`,
+		"not json":      `base=USD`,
+	}
+	for name, doc := range tests {
+		if _, err := ParseRates(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRateTableRate(t *testing.T) {
+	table := &RateTable{Base: "USD", Rates: map[string]*big.Rat{"USD": rat("1"), "EUR": rat("0.9"), "INR": rat("84")}}
+
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{from: "USD", to: "INR", want: "84"},
+		{from: "INR", to: "USD", want: "1/84"},
+		{from: "eur", to: "inr", want: "280/3"},
+		{from: "EUR", to: "EUR", want: "1"},
+	}
+	for _, tt := range tests {
+		got, err := table.Rate(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%s->%s: %v", tt.from, tt.to, err)
+		}
+		if got.Cmp(rat(tt.want)) != 0 {
+			t.Errorf("%s->%s = %s, want %s", tt.from, tt.to, got.RatString(), tt.want)
+		}
+	}
+
+	if _, err := table.Rate("USD", "XYZ"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Fatalf("got %v, want ErrUnknownCurrency", err)
+	}
+}
@@ -0,0 +1,141 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+// Service converts fares between currencies with exact rational arithmetic.
+// When the rate source fails the last table it returned is used, so an outage of the source
+// degrades to slightly stale prices rather than failed searches.
+type Service struct {
+	provider RateProvider
+
+	mu       sync.RWMutex
+	lastGood *RateTable
+}
+
+func NewService(provider RateProvider) *Service {
+	return &Service{provider: provider}
+}
+
+// Rates returns the current rate table, or the last good one when the source fails. Fetch it
+// once to convert many amounts rather than calling Convert for each.
+func (s *Service) Rates(ctx context.Context) (*RateTable, error) {
+	t, err := s.provider.Rates(ctx)
+	if err == nil {
+		s.mu.Lock()
+		s.lastGood = t
+		s.mu.Unlock()
+		return t, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lastGood != nil {
+		log.Printf("[currency.Service] rate source failed, using rates as of %s: %v", s.lastGood.AsOf.Format("2006-01-02"), err)
+		return s.lastGood, nil
+	}
+	return nil, fmt.Errorf("exchange rates unavailable: %w", err)
+}
+
+// Supported reports whether a currency can be converted to and from
+func (s *Service) Supported(ctx context.Context, code string) (bool, error) {
+	t, err := s.Rates(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := t.Rates[strings.ToUpper(code)]
+	return ok, nil
+}
+
+// Convert converts an amount, rounding the result to the nearest hundredth
+func (s *Service) Convert(ctx context.Context, amount money.Amount, from, to string) (money.Amount, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+	t, err := s.Rates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return t.Convert(amount, from, to)
+}
+
+// Convert converts an amount at the table's rates, rounding the result to the nearest hundredth
+func (t *RateTable) Convert(amount money.Amount, from, to string) (money.Amount, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+	rate, err := t.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return money.FromRat(rate.Mul(rate, amount.Rat()))
+}
+
+// convertFare converts both prices of a fare. The unified price is the same fare in the result
+// currency, so it stands in for the agency price when the agency currency has no rate.
+func convertFare(t *RateTable, fare models.Fare, resultCurrency, to string) (models.Fare, error) {
+	from := fare.Currency
+	if from == "" {
+		from = resultCurrency
+	}
+	if resultCurrency == "" {
+		fare.UnifiedPrice, resultCurrency = fare.Price, from
+	}
+
+	unified, err := t.Convert(fare.UnifiedPrice, resultCurrency, to)
+	if err != nil {
+		return fare, err
+	}
+	price, err := t.Convert(fare.Price, from, to)
+	if errors.Is(err, ErrUnknownCurrency) {
+		price, err = unified, nil
+	}
+	if err != nil {
+		return fare, err
+	}
+
+	fare.Price, fare.UnifiedPrice, fare.Currency = price, unified, to
+	return fare, nil
+}
+
+// ConvertResult returns a copy of result with every fare in the target currency. Each fare's
+// agency price is converted from its own currency and its unified price from the result currency.
+// An empty target converts to the result currency, so fares at least share one currency.
+func (s *Service) ConvertResult(ctx context.Context, result *models.FlightSearchResult, to string) (*models.FlightSearchResult, error) {
+	to = strings.ToUpper(to)
+	if to == "" {
+		to = strings.ToUpper(result.Currency)
+	}
+	if to == "" {
+		return result, nil
+	}
+
+	t, err := s.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *result
+	out.Currency = to
+	out.Itineraries = make([]models.Itinerary, len(result.Itineraries))
+	for i, it := range result.Itineraries {
+		fares := make([]models.Fare, len(it.Fares))
+		for j, fare := range it.Fares {
+			if fares[j], err = convertFare(t, fare, result.Currency, to); err != nil {
+				return nil, err
+			}
+		}
+		it.Fares = fares
+		out.Itineraries[i] = it
+	}
+	return &out, nil
+}
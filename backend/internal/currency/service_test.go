@@ -0,0 +1,126 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+func testTable() *RateTable {
+	return &RateTable{Base: "USD", Rates: map[string]*big.Rat{"USD": rat("1"), "EUR": rat("0.9"), "INR": rat("84"), "GBP": rat("0.5")}}
+}
+
+func TestConvertRounds(t *testing.T) {
+	table := testTable()
+
+	tests := []struct {
+		name     string
+		amount   money.Amount
+		from, to string
+		want     money.Amount
+	}{
+		{name: "down", amount: money.FromUnits(10, 0), from: "EUR", to: "INR", want: money.FromUnits(933, 33)},
+		{name: "half away from zero", amount: money.FromUnits(0, 1), from: "USD", to: "GBP", want: money.FromUnits(0, 1)},
+		{name: "up", amount: money.FromUnits(0, 3), from: "USD", to: "GBP", want: money.FromUnits(0, 2)},
+		{name: "same currency", amount: money.FromUnits(12, 34), from: "xyz", to: "XYZ", want: money.FromUnits(12, 34)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Convert(tt.amount, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertFare(t *testing.T) {
+	table := testTable()
+
+	tests := []struct {
+		name           string
+		fare           models.Fare
+		resultCurrency string
+		wantPrice      money.Amount
+		wantUnified    money.Amount
+	}{
+		{
+			name:           "both prices converted",
+			fare:           models.Fare{Currency: "EUR", Price: money.FromUnits(90, 0), UnifiedPrice: money.FromUnits(100, 0)},
+			resultCurrency: "USD",
+			wantPrice:      money.FromUnits(50, 0),
+			wantUnified:    money.FromUnits(50, 0),
+		},
+		{
+			name:           "unknown agency currency falls back to the unified price",
+			fare:           models.Fare{Currency: "XYZ", Price: money.FromUnits(7000, 0), UnifiedPrice: money.FromUnits(100, 0)},
+			resultCurrency: "USD",
+			wantPrice:      money.FromUnits(50, 0),
+			wantUnified:    money.FromUnits(50, 0),
+		},
+		{
+			name:           "fare without currency is in the result currency",
+			fare:           models.Fare{Price: money.FromUnits(84, 0), UnifiedPrice: money.FromUnits(84, 0)},
+			resultCurrency: "INR",
+			wantPrice:      money.FromUnits(0, 50),
+			wantUnified:    money.FromUnits(0, 50),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertFare(table, tt.fare, tt.resultCurrency, "GBP")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Price != tt.wantPrice || got.UnifiedPrice != tt.wantUnified || got.Currency != "GBP" {
+				t.Errorf("got %s/%s %s, want %s/%s GBP", got.Price, got.UnifiedPrice, got.Currency, tt.wantPrice, tt.wantUnified)
+			}
+		})
+	}
+
+	fare := models.Fare{Currency: "EUR", Price: money.FromUnits(1, 0), UnifiedPrice: money.FromUnits(1, 0)}
+	if _, err := convertFare(table, fare, "XYZ", "GBP"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Fatalf("unknown result currency: got %v, want ErrUnknownCurrency", err)
+	}
+}
+
+type flakyProvider struct {
+	table *RateTable
+	err   error
+}
+
+func (p *flakyProvider) Rates(ctx context.Context) (*RateTable, error) {
+	return p.table, p.err
+}
+
+func TestServiceRatesFallsBackToLastGood(t *testing.T) {
+	ctx := context.Background()
+	p := &flakyProvider{err: errors.New("source down")}
+	s := NewService(p)
+
+	if _, err := s.Rates(ctx); err == nil {
+		t.Fatal("expected an error before any table was fetched")
+	}
+
+	good := testTable()
+	p.table, p.err = good, nil
+	if got, err := s.Rates(ctx); err != nil || got != good {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	p.table, p.err = nil, errors.New("source down")
+	if got, err := s.Rates(ctx); err != nil || got != good {
+		t.Fatalf("got %v, %v, want the last good table", got, err)
+	}
+	amount, err := s.Convert(ctx, money.FromUnits(10, 0), "USD", "GBP")
+	if err != nil || amount != money.FromUnits(5, 0) {
+		t.Fatalf("got %s, %v", amount, err)
+	}
+}
@@ -0,0 +1,86 @@
+package enrich
+
+import (
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+)
+
+const (
+	// MinConnectionMinutes is the shortest change of planes within one airport considered safe
+	MinConnectionMinutes = 60
+	// MinAirportChangeMinutes is the shortest connection considered safe when it needs a
+	// transfer to another airport, like Heathrow to Gatwick
+	MinAirportChangeMinutes = 180
+)
+
+// red-eye flights leave late at night or in the small hours and land in the early morning,
+// by local time at each end
+const (
+	redEyeDepartFrom  = 21
+	redEyeDepartUntil = 4
+	redEyeArriveFrom  = 4
+	redEyeArriveUntil = 10
+)
+
+// connections flags the red-eye flights of a leg and fills in its layovers. Flight times must
+// already be in their airports' zones for durations and the overnight flag to be right; a
+// layover with an unknown time keeps a zero duration and is not flagged short or overnight.
+func (e *Enricher) connections(l *models.Leg) {
+	for i := range l.Segments {
+		l.Segments[i].RedEye = redEye(l.Segments[i])
+	}
+
+	l.Layovers = nil
+	for i := 1; i < len(l.Segments); i++ {
+		prev, next := l.Segments[i-1], l.Segments[i]
+		lay := models.Layover{
+			Airport:          prev.Destination,
+			DepartureAirport: next.Origin,
+			AirportChange:    prev.Destination != next.Origin,
+		}
+		// an airport change means collecting bags and checking in again whatever the ticket
+		lay.SelfTransfer = lay.AirportChange || !protected(prev, next)
+
+		if !prev.Arrival.IsZero() && !next.Departure.IsZero() {
+			lay.DurationMinutes = int(next.Departure.Sub(prev.Arrival).Minutes())
+			lay.Overnight = !sameDay(prev.Arrival, next.Departure)
+			safe := MinConnectionMinutes
+			if lay.AirportChange {
+				safe = MinAirportChangeMinutes
+			}
+			lay.Short = lay.DurationMinutes < safe
+		}
+		l.Layovers = append(l.Layovers, lay)
+	}
+}
+
+// protected guesses whether a connection is on one ticket: the flights share an airline, as
+// marketing or operating carrier. Alliance partners are not enough; separate tickets on two
+// members of one alliance are common and are not protected.
+func protected(prev, next models.FlightSegment) bool {
+	for _, a := range []string{prev.MarketingCarrier, prev.OperatingCarrier} {
+		for _, b := range []string{next.MarketingCarrier, next.OperatingCarrier} {
+			if a != "" && strings.EqualFold(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func redEye(s models.FlightSegment) bool {
+	if s.Departure.IsZero() || s.Arrival.IsZero() {
+		return false
+	}
+	dep, arr := s.Departure.Hour(), s.Arrival.Hour()
+	return (dep >= redEyeDepartFrom || dep < redEyeDepartUntil) && arr >= redEyeArriveFrom && arr < redEyeArriveUntil
+}
+
+// sameDay compares the local calendar dates of two times
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
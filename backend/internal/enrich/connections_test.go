@@ -0,0 +1,101 @@
+package enrich
+
+import (
+	"testing"
+	"time"
+
+	"stopover.backend/internal/models"
+)
+
+func TestConnections(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	flight := func(origin, destination, marketing, operating string, depart, arrive time.Time) models.FlightSegment {
+		return models.FlightSegment{Origin: origin, Destination: destination, MarketingCarrier: marketing, OperatingCarrier: operating, Departure: depart, Arrival: arrive}
+	}
+
+	tests := []struct {
+		name       string
+		prev, next models.FlightSegment
+		want       models.Layover
+	}{
+		{
+			name: "same carrier",
+			prev: flight("DEL", "BOM", "AI", "", at(1, 6, 0), at(1, 8, 0)),
+			next: flight("BOM", "DXB", "AI", "", at(1, 9, 30), at(1, 11, 0)),
+			want: models.Layover{Airport: "BOM", DepartureAirport: "BOM", DurationMinutes: 90},
+		},
+		{
+			name: "short",
+			prev: flight("DEL", "BOM", "AI", "", at(1, 6, 0), at(1, 8, 0)),
+			next: flight("BOM", "DXB", "AI", "", at(1, 8, 45), at(1, 11, 0)),
+			want: models.Layover{Airport: "BOM", DepartureAirport: "BOM", DurationMinutes: 45, Short: true},
+		},
+		{
+			name: "overnight",
+			prev: flight("DEL", "BOM", "AI", "", at(1, 20, 0), at(1, 22, 30)),
+			next: flight("BOM", "DXB", "AI", "", at(2, 6, 10), at(2, 8, 0)),
+			want: models.Layover{Airport: "BOM", DepartureAirport: "BOM", DurationMinutes: 460, Overnight: true},
+		},
+		{
+			name: "airport change is a self transfer with a longer safe time",
+			prev: flight("DEL", "LHR", "AI", "", at(1, 6, 0), at(1, 11, 0)),
+			next: flight("LGW", "JFK", "AI", "", at(1, 13, 30), at(1, 20, 0)),
+			want: models.Layover{Airport: "LHR", DepartureAirport: "LGW", DurationMinutes: 150, Short: true, AirportChange: true, SelfTransfer: true},
+		},
+		{
+			name: "codeshare on the operating carrier",
+			prev: flight("DEL", "FRA", "AI", "", at(1, 2, 0), at(1, 7, 0)),
+			next: flight("FRA", "JFK", "LH", "AI", at(1, 9, 0), at(1, 12, 0)),
+			want: models.Layover{Airport: "FRA", DepartureAirport: "FRA", DurationMinutes: 120},
+		},
+		{
+			name: "alliance partners are a self transfer",
+			prev: flight("DEL", "FRA", "AI", "", at(1, 2, 0), at(1, 7, 0)),
+			next: flight("FRA", "JFK", "LH", "LH", at(1, 9, 0), at(1, 12, 0)),
+			want: models.Layover{Airport: "FRA", DepartureAirport: "FRA", DurationMinutes: 120, SelfTransfer: true},
+		},
+		{
+			name: "unknown time",
+			prev: flight("DEL", "BOM", "AI", "", at(1, 6, 0), time.Time{}),
+			next: flight("BOM", "DXB", "AI", "", at(1, 6, 30), at(1, 11, 0)),
+			want: models.Layover{Airport: "BOM", DepartureAirport: "BOM"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leg := models.Leg{Segments: []models.FlightSegment{tt.prev, tt.next}}
+			(&Enricher{}).connections(&leg)
+			if len(leg.Layovers) != 1 || leg.Layovers[0] != tt.want {
+				t.Fatalf("got %+v, want %+v", leg.Layovers, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedEye(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 10, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name           string
+		depart, arrive time.Time
+		want           bool
+	}{
+		{name: "late departure, early arrival", depart: at(23, 30), arrive: at(6, 15), want: true},
+		{name: "small hours", depart: at(2, 0), arrive: at(7, 0), want: true},
+		{name: "late departure, late arrival", depart: at(21, 0), arrive: at(23, 50), want: false},
+		{name: "arrives after the morning window", depart: at(22, 0), arrive: at(10, 0), want: false},
+		{name: "day flight", depart: at(9, 0), arrive: at(12, 0), want: false},
+		{name: "unknown arrival", depart: at(23, 0), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redEye(models.FlightSegment{Departure: tt.depart, Arrival: tt.arrive}); got != tt.want {
+				t.Errorf("redEye = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/places"
+	"stopover.backend/internal/provider"
+)
+
+// DefaultLogoURL is the Travelpayouts airline logo service, formatted with the IATA code
+const DefaultLogoURL = "https://pics.avs.io/120/120/%s.png"
+
+// Enricher fills in the info fields of flights from local reference data. It is immutable once
+// built and safe for concurrent use.
+type Enricher struct {
+	airports *places.Index
+	airlines map[string]Airline
+	aircraft map[string]Aircraft
+	logoURL  string
+
+	locations sync.Map // time zone name to *time.Location
+}
+
+// NewEnricher builds an enricher; logoURL is formatted with an airline's IATA code and defaults
+// to DefaultLogoURL
+func NewEnricher(airports *places.Index, airlines []Airline, aircraft []Aircraft, logoURL string) *Enricher {
+	if logoURL == "" {
+		logoURL = DefaultLogoURL
+	}
+	e := &Enricher{
+		airports: airports,
+		airlines: make(map[string]Airline, len(airlines)),
+		aircraft: make(map[string]Aircraft, 2*len(aircraft)),
+		logoURL:  logoURL,
+	}
+	for _, a := range airlines {
+		e.airlines[strings.ToUpper(a.Code)] = a
+	}
+	for _, a := range aircraft {
+		if a.IATA != "" {
+			e.aircraft[strings.ToUpper(a.IATA)] = a
+		}
+		if a.ICAO != "" {
+			e.aircraft[strings.ToUpper(a.ICAO)] = a
+		}
+	}
+	return e
+}
+
+// Itineraries enriches every flight of the itineraries in place. Details the supplier sent are
+// kept; only missing ones are looked up.
+func (e *Enricher) Itineraries(its []models.Itinerary) {
+	for i := range its {
+		for j := range its[i].Legs {
+			e.leg(&its[i].Legs[j])
+		}
+	}
+}
+
+// leg enriches the flights of a leg and analyses its connections. Times of airports the supplier
+// gave no time zone for were read as UTC; when the zone is found here they are moved to it,
+// keeping the local clock time.
+func (e *Enricher) leg(l *models.Leg) {
+	rezoned := false
+	for k := range l.Segments {
+		if e.segment(&l.Segments[k]) {
+			rezoned = true
+		}
+	}
+
+	if rezoned {
+		first, last := l.Segments[0], l.Segments[len(l.Segments)-1]
+		l.Departure, l.Arrival = first.Departure, last.Arrival
+		if zoned(first.OriginAirport) && zoned(last.DestinationAirport) {
+			l.DurationMinutes = int(l.Arrival.Sub(l.Departure).Minutes())
+		}
+	}
+	e.connections(l)
+}
+
+// segment enriches one flight and reports whether any of its times was moved to a new zone
+func (e *Enricher) segment(s *models.FlightSegment) bool {
+	originZoned, destinationZoned := zoned(s.OriginAirport), zoned(s.DestinationAirport)
+	s.OriginAirport = e.airport(s.Origin, s.OriginAirport)
+	s.DestinationAirport = e.airport(s.Destination, s.DestinationAirport)
+
+	rezoned := false
+	if !originZoned && zoned(s.OriginAirport) {
+		s.Departure, rezoned = e.inZone(s.Departure, s.OriginAirport.TimeZone), true
+	}
+	if !destinationZoned && zoned(s.DestinationAirport) {
+		s.Arrival, rezoned = e.inZone(s.Arrival, s.DestinationAirport.TimeZone), true
+	}
+
+	s.MarketingAirline = e.airline(s.MarketingCarrier, s.MarketingAirline)
+	s.OperatingAirline = e.airline(s.OperatingCarrier, s.OperatingAirline)
+	if s.AircraftType == nil {
+		s.AircraftType = e.Aircraft(s.Aircraft)
+	}
+	return rezoned
+}
+
+func zoned(a *models.AirportInfo) bool {
+	return a != nil && a.TimeZone != ""
+}
+
+// inZone keeps the clock time of t and moves it to the named zone; only times the mapper fell
+// back to UTC for are moved
+func (e *Enricher) inZone(t time.Time, zone string) time.Time {
+	if t.IsZero() || t.Location() != time.UTC {
+		return t
+	}
+	loc, ok := e.locations.Load(zone)
+	if !ok {
+		l, err := time.LoadLocation(zone)
+		if err != nil {
+			return t
+		}
+		loc, _ = e.locations.LoadOrStore(zone, l)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc.(*time.Location))
+}
+
+// airport completes the supplier's details of an airport from the places index
+func (e *Enricher) airport(code string, info *models.AirportInfo) *models.AirportInfo {
+	a, ok := e.airports.Airport(code)
+	if !ok {
+		return info
+	}
+	if info == nil {
+		return &models.AirportInfo{Code: a.Code, Name: a.Name, City: a.City, CityCode: a.CityCode, Country: a.Country, TimeZone: a.TimeZone}
+	}
+	if info.City == "" {
+		info.City, info.CityCode = a.City, a.CityCode
+	}
+	if info.Country == "" {
+		info.Country = a.Country
+	}
+	if info.TimeZone == "" {
+		info.TimeZone = a.TimeZone
+	}
+	return info
+}
+
+// airline names an airline from the reference data when the supplier did not and adds its logo.
+// Unknown airlines keep their code as the name since the logo service covers far more of them.
+func (e *Enricher) airline(code string, info *models.AirlineInfo) *models.AirlineInfo {
+	if code == "" {
+		return info
+	}
+	if info == nil {
+		info = &models.AirlineInfo{Code: code, Name: code}
+		if a, ok := e.airlines[strings.ToUpper(code)]; ok {
+			info.Name = a.Name
+		}
+	}
+	if info.LogoURL == "" {
+		info.LogoURL = fmt.Sprintf(e.logoURL, strings.ToUpper(code))
+	}
+	return info
+}
+
+// Aircraft decodes an IATA or ICAO aircraft type code. Suppliers sometimes send a model name
+// instead of a code; that is passed through as the name.
+func (e *Enricher) Aircraft(code string) *models.AircraftInfo {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil
+	}
+	if a, ok := e.aircraft[strings.ToUpper(code)]; ok {
+		return &models.AircraftInfo{Code: code, Name: a.Name, Manufacturer: a.Manufacturer}
+	}
+	if len(code) > 4 {
+		return &models.AircraftInfo{Code: code, Name: code}
+	}
+	return nil
+}
+
+// Searcher enriches the results of another searcher. It belongs below any cache so results are
+// enriched once, before they are shared.
+type Searcher struct {
+	next     provider.Searcher
+	enricher *Enricher
+}
+
+func NewSearcher(next provider.Searcher, enricher *Enricher) *Searcher {
+	return &Searcher{next: next, enricher: enricher}
+}
+
+func (s *Searcher) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	res, err := s.next.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.enricher.Itineraries(res.Itineraries)
+	return res, nil
+}
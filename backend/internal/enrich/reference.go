@@ -0,0 +1,88 @@
+// Package enrich attaches display details to itineraries: airline names and logos, airport names,
+// cities and time zones, and aircraft types. Supplier metadata wins; the reference data bundled
+// here and the places index fill in whatever the supplier left out.
+package enrich
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	//go:embed airlines.csv
+	defaultAirlines []byte
+	//go:embed alliances.csv
+	defaultAlliances []byte
+	//go:embed aircraft.csv
+	defaultAircraft []byte
+)
+
+// Airline is an airline by its IATA designator; Alliance is star, oneworld, skyteam or empty
+type Airline struct {
+	Code        string
+	Name        string
+	CountryCode string
+	Alliance    string
+}
+
+// Aircraft is an aircraft type by its IATA and ICAO type codes; suppliers send either
+type Aircraft struct {
+	IATA         string
+	ICAO         string
+	Name         string
+	Manufacturer string
+}
+
+// DefaultAirlines returns the embedded airlines with their alliance membership
+func DefaultAirlines() []Airline {
+	rows, err := readTable(bytes.NewReader(defaultAirlines), "iata", "name", "country")
+	if err != nil {
+		panic(fmt.Errorf("embedded airlines: %w", err))
+	}
+	memberships, err := readTable(bytes.NewReader(defaultAlliances), "iata", "alliance")
+	if err != nil {
+		panic(fmt.Errorf("embedded alliances: %w", err))
+	}
+	alliances := make(map[string]string, len(memberships))
+	for _, r := range memberships {
+		alliances[r[0]] = r[1]
+	}
+
+	airlines := make([]Airline, 0, len(rows))
+	for _, r := range rows {
+		airlines = append(airlines, Airline{Code: r[0], Name: r[1], CountryCode: r[2], Alliance: alliances[r[0]]})
+	}
+	return airlines
+}
+
+// DefaultAircraft returns the embedded aircraft types
+func DefaultAircraft() []Aircraft {
+	rows, err := readTable(bytes.NewReader(defaultAircraft), "iata", "icao", "name", "manufacturer")
+	if err != nil {
+		panic(fmt.Errorf("embedded aircraft: %w", err))
+	}
+	aircraft := make([]Aircraft, 0, len(rows))
+	for _, r := range rows {
+		aircraft = append(aircraft, Aircraft{IATA: r[0], ICAO: r[1], Name: r[2], Manufacturer: r[3]})
+	}
+	return aircraft
+}
+
+// readTable reads a csv with the given header and returns the rows after it
+func readTable(r io.Reader, header ...string) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(header)
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(header, ",") {
+		return nil, fmt.Errorf("expected header %q", strings.Join(header, ","))
+	}
+	return rows[1:], nil
+}
@@ -0,0 +1,37 @@
+package enrich
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefaultAirlinesJoinAlliances(t *testing.T) {
+	airlines := make(map[string]Airline)
+	for _, a := range DefaultAirlines() {
+		airlines[a.Code] = a
+	}
+
+	memberships, err := readTable(bytes.NewReader(defaultAlliances), "iata", "alliance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range memberships {
+		a, ok := airlines[r[0]]
+		if !ok {
+			t.Errorf("alliances.csv lists %s, which is not in airlines.csv", r[0])
+			continue
+		}
+		if a.Alliance != r[1] {
+			t.Errorf("%s alliance = %q, want %q", r[0], a.Alliance, r[1])
+		}
+		switch r[1] {
+		case "star", "oneworld", "skyteam":
+		default:
+			t.Errorf("%s has unknown alliance %q", r[0], r[1])
+		}
+	}
+
+	if a := airlines["6E"]; a.Alliance != "" {
+		t.Errorf("6E alliance = %q, want none", a.Alliance)
+	}
+}
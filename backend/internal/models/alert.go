@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"stopover.backend/pkg/money"
+)
+
+// a user's watch on the cheapest fare of a route over a range of departure dates.
+// Threshold and prices are in Currency; StayDays makes it a round trip of that length.
+type PriceAlert struct {
+	AlertId       int64         `json:"id"`
+	UserId        int64         `json:"-"`
+	Origin        string        `json:"origin"`
+	Destination   string        `json:"destination"`
+	DepartFrom    string        `json:"depart_from"`
+	DepartTo      string        `json:"depart_to"`
+	StayDays      *int          `json:"stay_days,omitempty"`
+	Adults        int           `json:"adults"`
+	TripClass     string        `json:"trip_class"`
+	Currency      string        `json:"currency"`
+	Threshold     money.Amount  `json:"threshold"`
+	Channel       string        `json:"channel"`
+	Target        string        `json:"target,omitempty"`
+	UserIP        string        `json:"-"`
+	Active        bool          `json:"active"`
+	LastPrice     *money.Amount `json:"last_price,omitempty"`
+	LastCheckedAt *time.Time    `json:"last_checked_at,omitempty"`
+	NextCheckAt   time.Time     `json:"next_check_at"`
+	NotifiedPrice *money.Amount `json:"notified_price,omitempty"`
+	NotifiedAt    *time.Time    `json:"notified_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// create and update price alert api request; depart_to defaults to depart_from
+type PriceAlertRequest struct {
+	Origin      string       `json:"origin" validate:"required,len=3,alpha"`
+	Destination string       `json:"destination" validate:"required,len=3,alpha"`
+	DepartFrom  string       `json:"depart_from" validate:"required,datetime=2006-01-02"`
+	DepartTo    string       `json:"depart_to" validate:"omitempty,datetime=2006-01-02"`
+	StayDays    *int         `json:"stay_days" validate:"omitempty,min=1,max=60"`
+	Adults      int          `json:"adults" validate:"omitempty,min=1,max=9"`
+	Cabin       string       `json:"cabin" validate:"omitempty,oneof=economy business first"`
+	Currency    string       `json:"currency" validate:"omitempty,len=3,alpha"`
+	Threshold   money.Amount `json:"threshold" validate:"required,gt=0"`
+	Channel     string       `json:"channel" validate:"required,oneof=log webhook email"`
+	Target      string       `json:"target" validate:"omitempty,max=500"`
+	Active      *bool        `json:"active"`
+}
+
+// the outcome of one background check of an alert; Price is zero when nothing was found
+type PriceCheck struct {
+	AlertId       int64
+	DepartureDate string
+	ReturnDate    string
+	Price         money.Amount
+	Currency      string
+	SearchId      string
+	Error         string
+	NextCheckAt   time.Time
+}
+
+// one stored check of an alert, as returned by the price history api
+type PricePoint struct {
+	DepartureDate string        `json:"departure_date,omitempty"`
+	ReturnDate    string        `json:"return_date,omitempty"`
+	Price         *money.Amount `json:"price,omitempty"`
+	Currency      string        `json:"currency"`
+	Error         string        `json:"error,omitempty"`
+	CheckedAt     time.Time     `json:"checked_at"`
+}
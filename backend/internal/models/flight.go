@@ -0,0 +1,39 @@
+package models
+
+// flight search parameters shared by the query string and json body endpoints
+type FlightSearchParams struct {
+	Origin      string `json:"origin" form:"origin" validate:"required,len=3,alpha"`
+	Destination string `json:"destination" form:"destination" validate:"required,len=3,alpha"`
+	Departure   string `json:"departure" form:"departure" validate:"required,datetime=2006-01-02"`
+	Return      string `json:"return" form:"return" validate:"omitempty,datetime=2006-01-02"`
+	Adults      int    `json:"adults" form:"adults,default=1" validate:"omitempty,min=1,max=9"`
+	Children    int    `json:"children" form:"children" validate:"min=0,max=8"`
+	Infants     int    `json:"infants" form:"infants" validate:"min=0,max=9"`
+	Cabin       string `json:"cabin" form:"cabin,default=economy" validate:"omitempty,oneof=economy business first"`
+	Locale      string `json:"locale" form:"locale,default=en" validate:"omitempty,oneof=en ru de es fr it pl th"`
+	TripType    string `json:"trip_type" form:"tripType,default=one-way" validate:"omitempty,oneof=one-way round-trip"`
+}
+
+// create search session api response
+type CreateSearchResponse struct {
+	Id        string `json:"id"`
+	SearchId  string `json:"search_id"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// multi-city search api request; legs are flown in the given order
+type MultiCitySearchRequest struct {
+	Legs     []SearchLeg `json:"legs" validate:"required,min=1,max=6,dive"`
+	Adults   int         `json:"adults" validate:"omitempty,min=1,max=9"`
+	Children int         `json:"children" validate:"min=0,max=8"`
+	Infants  int         `json:"infants" validate:"min=0,max=9"`
+	Cabin    string      `json:"cabin" validate:"omitempty,oneof=economy business first"`
+	Locale   string      `json:"locale" validate:"omitempty,oneof=en ru de es fr it pl th"`
+}
+
+// one origin/destination/date leg of a multi-city search
+type SearchLeg struct {
+	Origin      string `json:"origin" validate:"required,len=3,alpha"`
+	Destination string `json:"destination" validate:"required,len=3,alpha"`
+	Date        string `json:"date" validate:"required,datetime=2006-01-02"`
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"time"
+
+	"stopover.backend/pkg/money"
+)
+
+// provider-neutral flight search
+type FlightQuery struct {
+	Segments  []QuerySegment
+	Adults    int
+	Children  int
+	Infants   int
+	TripClass string
+	Locale    string
+	UserIP    string
+}
+
+// one origin/destination/date leg of a flight query
+type QuerySegment struct {
+	Origin      string
+	Destination string
+	Date        string
+}
+
+// Itinerary is a bookable combination of flights with every fare found for it.
+// Id is derived from the flights themselves so the same trip from different providers merges.
+type Itinerary struct {
+	Id              string   `json:"id"`
+	Legs            []Leg    `json:"legs"`
+	Carriers        []string `json:"carriers"`
+	IsDirect        bool     `json:"is_direct"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Fares           []Fare   `json:"fares"`
+}
+
+// Leg is one requested origin/destination of an itinerary, flown as one or more segments
+// with a layover between each pair of them
+type Leg struct {
+	Origin          string          `json:"origin"`
+	Destination     string          `json:"destination"`
+	Departure       time.Time       `json:"departure"`
+	Arrival         time.Time       `json:"arrival"`
+	DurationMinutes int             `json:"duration_minutes"`
+	Stops           int             `json:"stops"`
+	Segments        []FlightSegment `json:"segments"`
+	Layovers        []Layover       `json:"layovers,omitempty"`
+}
+
+// Layover is the wait between two flights of a leg. Airport is where the first flight lands and
+// DepartureAirport where the next one leaves, which differ when the connection needs a transfer
+// across town. Short connections are below the minimum time considered safe for the connection;
+// self transfers are likely on separate tickets, so bags are collected and a missed flight is
+// not rebooked.
+type Layover struct {
+	Airport          string `json:"airport"`
+	DepartureAirport string `json:"departure_airport"`
+	DurationMinutes  int    `json:"duration_minutes"`
+	Short            bool   `json:"short"`
+	Overnight        bool   `json:"overnight"`
+	AirportChange    bool   `json:"airport_change"`
+	SelfTransfer     bool   `json:"self_transfer"`
+}
+
+// FlightSegment is a single flight; times are in the local time zone of each airport.
+// RedEye marks a flight through the night. The info fields describe the codes next to them
+// and are nil when nothing is known.
+type FlightSegment struct {
+	Origin             string        `json:"origin"`
+	Destination        string        `json:"destination"`
+	Departure          time.Time     `json:"departure"`
+	Arrival            time.Time     `json:"arrival"`
+	MarketingCarrier   string        `json:"marketing_carrier"`
+	OperatingCarrier   string        `json:"operating_carrier"`
+	FlightNumber       string        `json:"flight_number"`
+	Aircraft           string        `json:"aircraft"`
+	TripClass          string        `json:"trip_class"`
+	DurationMinutes    int           `json:"duration_minutes"`
+	RedEye             bool          `json:"red_eye"`
+	OriginAirport      *AirportInfo  `json:"origin_airport,omitempty"`
+	DestinationAirport *AirportInfo  `json:"destination_airport,omitempty"`
+	MarketingAirline   *AirlineInfo  `json:"marketing_airline,omitempty"`
+	OperatingAirline   *AirlineInfo  `json:"operating_airline,omitempty"`
+	AircraftType       *AircraftInfo `json:"aircraft_type,omitempty"`
+}
+
+// display details of an airport
+type AirportInfo struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	City     string `json:"city,omitempty"`
+	CityCode string `json:"city_code,omitempty"`
+	Country  string `json:"country,omitempty"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// display details of an airline
+type AirlineInfo struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	LogoURL string `json:"logo_url,omitempty"`
+}
+
+// decoded aircraft type
+type AircraftInfo struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+}
+
+// Fare is the price one agency (gate) asks for an itinerary
+type Fare struct {
+	Provider     string       `json:"provider"`
+	Gate         string       `json:"gate"`
+	Currency     string       `json:"currency"`
+	Price        money.Amount `json:"price"`
+	UnifiedPrice money.Amount `json:"unified_price"`
+	BookingToken string       `json:"booking_token,omitempty"`
+	SearchID     string       `json:"search_id,omitempty"`
+}
+
+// Priced reports whether the fare has a unified price; one the supplier could not convert has none
+func (f Fare) Priced() bool {
+	return f.UnifiedPrice > 0
+}
+
+// Cheaper orders fares by unified price with unpriced fares after every priced one
+func (f Fare) Cheaper(than Fare) bool {
+	if f.Priced() != than.Priced() {
+		return f.Priced()
+	}
+	return f.UnifiedPrice < than.UnifiedPrice
+}
+
+// BestFare returns the cheapest priced fare of the itinerary
+func (it Itinerary) BestFare() (Fare, bool) {
+	var best Fare
+	found := false
+	for _, f := range it.Fares {
+		if f.Priced() && (!found || f.UnifiedPrice < best.UnifiedPrice) {
+			best, found = f, true
+		}
+	}
+	return best, found
+}
+
+// aggregated itineraries from every provider that answered
+type FlightSearchResult struct {
+	Itineraries []Itinerary       `json:"itineraries"`
+	Currency    string            `json:"currency,omitempty"`
+	Providers   []ProviderOutcome `json:"providers"`
+}
+
+// per-provider result summary of an aggregated search
+type ProviderOutcome struct {
+	Name        string `json:"name"`
+	Itineraries int    `json:"itineraries"`
+	Error       string `json:"error,omitempty"`
+}
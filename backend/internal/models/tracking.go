@@ -0,0 +1,71 @@
+package models
+
+import "stopover.backend/pkg/money"
+
+// one user search that returned results, recorded for conversion reporting
+type SearchSession struct {
+	ResultId      string
+	SearchId      string
+	Origin        string
+	Destination   string
+	DepartureDate string
+	ReturnDate    string
+	Segments      int
+	TripClass     string
+	Adults        int
+	Children      int
+	Infants       int
+	Itineraries   int
+	MinPrice      money.Amount
+	Currency      string
+	UserId        int64
+	UserIP        string
+}
+
+// one redirect of a user from a fare to the booking agency
+type ClickOut struct {
+	SearchId      string
+	ResultId      string
+	Term          string
+	Gate          string
+	AgencyClickId string
+	AgencyURL     string
+	Origin        string
+	Destination   string
+	Price         money.Amount
+	Currency      string
+	UserId        int64
+	UserIP        string
+	UserAgent     string
+	Referer       string
+}
+
+// click report api request; dates are inclusive and default to the last 30 days
+type ClickReportRequest struct {
+	From        string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To          string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+	Origin      string `form:"origin" validate:"omitempty,len=3,alpha"`
+	Destination string `form:"destination" validate:"omitempty,len=3,alpha"`
+	Gate        string `form:"gate" validate:"omitempty,max=20"`
+}
+
+// clicks of one route and gate on one day; value sums the clicked prices per currency
+type ClickReportRow struct {
+	Day         string       `json:"day"`
+	Origin      string       `json:"origin"`
+	Destination string       `json:"destination"`
+	Gate        string       `json:"gate"`
+	Currency    string       `json:"currency"`
+	Clicks      int64        `json:"clicks"`
+	Users       int64        `json:"users"`
+	Searches    int64        `json:"searches"`
+	Value       money.Amount `json:"value"`
+}
+
+// click report api response
+type ClickReportResponse struct {
+	From  string            `json:"from"`
+	To    string            `json:"to"`
+	Total int64             `json:"total_clicks"`
+	Rows  []*ClickReportRow `json:"rows"`
+}
@@ -0,0 +1,127 @@
+package places
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	kmPerDegree   = earthRadiusKm * math.Pi / 180
+	// gridDegrees is the size of a grid cell; a radius query only measures airports in the
+	// cells it overlaps
+	gridDegrees = 1.0
+)
+
+type cell struct {
+	lat, lon int
+}
+
+func cellOf(lat, lon float64) cell {
+	return cell{lat: int(math.Floor(lat / gridDegrees)), lon: int(math.Floor(lon / gridDegrees))}
+}
+
+// NearbyAirport is an airport and its distance from the point searched around
+type NearbyAirport struct {
+	Airport    Airport
+	DistanceKm float64
+}
+
+// DistanceKm is the great-circle distance between two points by the haversine formula
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rlat1, rlat2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rlat1)*math.Cos(rlat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Nearby returns up to limit airports within radiusKm of a point, closest first
+func (ix *Index) Nearby(lat, lon, radiusKm float64, limit int) []NearbyAirport {
+	if radiusKm <= 0 || limit <= 0 {
+		return nil
+	}
+
+	var out []NearbyAirport
+	ix.scanCells(lat, lon, radiusKm, func(id int32) {
+		a := ix.airports[id]
+		if d := DistanceKm(lat, lon, a.Lat, a.Lon); d <= radiusKm {
+			out = append(out, NearbyAirport{Airport: a, DistanceKm: d})
+		}
+	})
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DistanceKm != out[j].DistanceKm {
+			return out[i].DistanceKm < out[j].DistanceKm
+		}
+		return out[i].Airport.Code < out[j].Airport.Code
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// NearbyAirports returns the airports within radiusKm of the airport or city with the given code,
+// leaving out that airport or the city's own airports. A city is measured from its busiest airport.
+func (ix *Index) NearbyAirports(code string, radiusKm float64, limit int) ([]NearbyAirport, bool) {
+	exclude := map[string]bool{}
+	origin, ok := ix.Airport(code)
+	if ok {
+		exclude[origin.Code] = true
+	} else {
+		city, ok := ix.City(code)
+		if !ok {
+			return nil, false
+		}
+		for _, c := range city.Airports {
+			exclude[c] = true
+		}
+		origin, _ = ix.Airport(city.Airports[0])
+	}
+
+	found := ix.Nearby(origin.Lat, origin.Lon, radiusKm, limit+len(exclude))
+	out := found[:0]
+	for _, n := range found {
+		if !exclude[n.Airport.Code] {
+			out = append(out, n)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, true
+}
+
+// scanCells calls fn for every airport in the grid cells overlapping the radius around a point
+func (ix *Index) scanCells(lat, lon, radiusKm float64, fn func(int32)) {
+	dLat := radiusKm / kmPerDegree
+	minLat, maxLat := math.Max(-90, lat-dLat), math.Min(90, lat+dLat)
+
+	// longitude degrees shrink towards the poles; size the window for the widest latitude
+	dLon := 180.0
+	if widest := math.Max(math.Abs(minLat), math.Abs(maxLat)); widest < 89 {
+		dLon = math.Min(180, dLat/math.Cos(widest*math.Pi/180))
+	}
+
+	lo, hi := cellOf(minLat, lon-dLon), cellOf(maxLat, lon+dLon)
+	lonCells := hi.lon - lo.lon + 1
+	if span := int(360 / gridDegrees); lonCells > span {
+		lonCells = span
+	}
+
+	for cy := lo.lat; cy <= hi.lat; cy++ {
+		for i := 0; i < lonCells; i++ {
+			for _, id := range ix.grid[cell{lat: cy, lon: wrapLonCell(lo.lon + i)}] {
+				fn(id)
+			}
+		}
+	}
+}
+
+// wrapLonCell maps a longitude cell index past the antimeridian back into [-180, 180)
+func wrapLonCell(c int) int {
+	span := int(360 / gridDegrees)
+	half := span / 2
+	return ((c+half)%span+span)%span - half
+}
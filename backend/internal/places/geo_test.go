@@ -0,0 +1,103 @@
+package places
+
+import (
+	"math"
+	"testing"
+)
+
+func londonAirports() []Airport {
+	return []Airport{
+		{Code: "LHR", Name: "Heathrow Airport", CityCode: "LON", City: "London", CountryCode: "GB", Country: "United Kingdom", Lat: 51.4700, Lon: -0.4543, Passengers: 79200},
+		{Code: "LGW", Name: "Gatwick Airport", CityCode: "LON", City: "London", CountryCode: "GB", Country: "United Kingdom", Lat: 51.1537, Lon: -0.1821, Passengers: 40900},
+		{Code: "STN", Name: "Stansted Airport", CityCode: "LON", City: "London", CountryCode: "GB", Country: "United Kingdom", Lat: 51.8860, Lon: 0.2389, Passengers: 28000},
+		{Code: "LTN", Name: "Luton Airport", CityCode: "LON", City: "London", CountryCode: "GB", Country: "United Kingdom", Lat: 51.8747, Lon: -0.3683, Passengers: 16400},
+		{Code: "LCY", Name: "London City Airport", CityCode: "LON", City: "London", CountryCode: "GB", Country: "United Kingdom", Lat: 51.5048, Lon: 0.0495, Passengers: 3400},
+		{Code: "SOU", Name: "Southampton Airport", CityCode: "SOU", City: "Southampton", CountryCode: "GB", Country: "United Kingdom", Lat: 50.9503, Lon: -1.3568, Passengers: 900},
+		{Code: "LDB", Name: "Londrina Airport", CityCode: "LDB", City: "Londrina", CountryCode: "BR", Country: "Brazil", Lat: -23.3336, Lon: -51.1301, Passengers: 1100},
+	}
+}
+
+func codes(found []NearbyAirport) []string {
+	out := make([]string, 0, len(found))
+	for _, n := range found {
+		out = append(out, n.Airport.Code)
+	}
+	return out
+}
+
+func TestNearbyAcrossTheAntimeridian(t *testing.T) {
+	ix := NewIndex([]Airport{
+		{Code: "TVU", CityCode: "TVU", Lat: -16.6906, Lon: 179.8770},
+		{Code: "SVU", CityCode: "SVU", Lat: -16.8028, Lon: 179.3410},
+		{Code: "EST", CityCode: "EST", Lat: -16.7000, Lon: -179.9500},
+		{Code: "FAR", CityCode: "FAR", Lat: -16.7000, Lon: -170.0000},
+	})
+
+	got := codes(ix.Nearby(-16.7, 179.99, 100, 10))
+	if len(got) != 3 || got[0] != "EST" || got[1] != "TVU" || got[2] != "SVU" {
+		t.Fatalf("east of the antimeridian: got %v, want [EST TVU SVU]", got)
+	}
+	got = codes(ix.Nearby(-16.7, -179.99, 30, 10))
+	if len(got) != 2 || got[0] != "EST" || got[1] != "TVU" {
+		t.Fatalf("west of the antimeridian: got %v, want [EST TVU]", got)
+	}
+}
+
+func TestNearbyAirportsLeavesOutTheOrigin(t *testing.T) {
+	ix := NewIndex(londonAirports())
+
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{name: "city leaves out all of its airports", code: "LON", want: []string{"SOU"}},
+		{name: "airport leaves out only itself", code: "LHR", want: []string{"LCY", "LGW", "LTN", "STN", "SOU"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, ok := ix.NearbyAirports(tt.code, 150, 10)
+			if !ok {
+				t.Fatalf("%s not found", tt.code)
+			}
+			got := codes(found)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if found, _ := ix.NearbyAirports("LHR", 150, 2); len(found) != 2 {
+		t.Fatalf("got %d airports, want the limit of 2", len(found))
+	}
+	if _, ok := ix.NearbyAirports("XXX", 150, 10); ok {
+		t.Fatal("unknown code reported as found")
+	}
+}
+
+func TestWrapLonCell(t *testing.T) {
+	tests := map[int]int{0: 0, 179: 179, 180: -180, 181: -179, -180: -180, -181: 179, 359: -1}
+	for in, want := range tests {
+		if got := wrapLonCell(in); got != want {
+			t.Errorf("wrapLonCell(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	if d := DistanceKm(51.47, -0.4543, 51.47, -0.4543); d != 0 {
+		t.Fatalf("same point: %f", d)
+	}
+	// a degree of latitude is about 111 km everywhere
+	if d := DistanceKm(10, 20, 11, 20); math.Abs(d-111.19) > 0.1 {
+		t.Fatalf("one degree: %f", d)
+	}
+	if a, b := DistanceKm(0, 179.5, 0, -179.5), DistanceKm(0, -0.5, 0, 0.5); math.Abs(a-b) > 1e-9 {
+		t.Fatalf("across the antimeridian %f, across Greenwich %f", a, b)
+	}
+}
@@ -0,0 +1,268 @@
+package places
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchKind says how an airport matched a query; lower kinds rank first
+type MatchKind int
+
+const (
+	MatchCode       MatchKind = iota // the airport code itself
+	MatchCityCode                    // the code of the airport's city
+	MatchCity                        // a prefix of the city name
+	MatchCityAlias                   // a prefix of another name shared by every airport of the city
+	MatchName                        // a prefix of the airport name
+	MatchAlias                       // a prefix of another name of the airport alone
+	MatchCodePrefix                  // a prefix of the airport code
+	MatchCountry                     // a prefix of the country name
+	MatchFuzzy                       // a city, name or alias with a typo
+)
+
+// queries shorter than this are never matched fuzzily; short typos match almost anything
+const minFuzzyRunes = 4
+
+// Match is one search result
+type Match struct {
+	Airport Airport
+	Kind    MatchKind
+}
+
+type term struct {
+	key     string
+	runes   []rune
+	airport int32
+	kind    MatchKind
+}
+
+// Index answers prefix and typo-tolerant airport lookups from memory. It is immutable once
+// built and safe for concurrent use.
+type Index struct {
+	airports []Airport
+	byCode   map[string]int32
+	cities   map[string]*City
+	byCity   map[string][]int32
+	grid     map[cell][]int32
+	terms    []term
+}
+
+func NewIndex(airports []Airport) *Index {
+	ix := &Index{
+		airports: airports,
+		byCode:   make(map[string]int32, len(airports)),
+		cities:   make(map[string]*City),
+		byCity:   make(map[string][]int32),
+		grid:     make(map[cell][]int32),
+	}
+
+	for i, a := range airports {
+		id := int32(i)
+		ix.byCode[a.Code] = id
+		ix.byCity[a.CityCode] = append(ix.byCity[a.CityCode], id)
+		ix.grid[cellOf(a.Lat, a.Lon)] = append(ix.grid[cellOf(a.Lat, a.Lon)], id)
+	}
+
+	for i, a := range airports {
+		id := int32(i)
+		ix.addTerms(id, MatchCodePrefix, a.Code)
+		ix.addTerms(id, MatchCity, a.City)
+		ix.addTerms(id, MatchName, a.Name)
+		ix.addTerms(id, MatchCountry, a.Country)
+		for _, alias := range a.Aliases {
+			kind := MatchAlias
+			if ix.cityWideAlias(a.CityCode, alias) {
+				kind = MatchCityAlias
+			}
+			ix.addTerms(id, kind, alias)
+		}
+	}
+
+	for code, ids := range ix.byCity {
+		ix.sortByTraffic(ids)
+		first := airports[ids[0]]
+		city := &City{Code: code, Name: first.City, CountryCode: first.CountryCode, Country: first.Country}
+		for _, id := range ids {
+			city.Airports = append(city.Airports, airports[id].Code)
+		}
+		ix.cities[code] = city
+	}
+
+	sort.Slice(ix.terms, func(i, j int) bool {
+		if ix.terms[i].key != ix.terms[j].key {
+			return ix.terms[i].key < ix.terms[j].key
+		}
+		return ix.terms[i].airport < ix.terms[j].airport
+	})
+	return ix
+}
+
+// cityWideAlias reports whether every airport of a city has the alias, which makes it another
+// name of the city, like Bombay, rather than of one airport, like Fiumicino
+func (ix *Index) cityWideAlias(cityCode, alias string) bool {
+	want := normalize(alias)
+	for _, id := range ix.byCity[cityCode] {
+		found := false
+		for _, other := range ix.airports[id].Aliases {
+			if normalize(other) == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (ix *Index) addTerms(id int32, kind MatchKind, text string) {
+	for _, p := range phrases(text) {
+		ix.terms = append(ix.terms, term{key: p, runes: []rune(p), airport: id, kind: kind})
+	}
+}
+
+// Len returns the number of airports in the index
+func (ix *Index) Len() int {
+	return len(ix.airports)
+}
+
+// Airport looks up an airport by its IATA code
+func (ix *Index) Airport(code string) (Airport, bool) {
+	id, ok := ix.byCode[strings.ToUpper(code)]
+	if !ok {
+		return Airport{}, false
+	}
+	return ix.airports[id], true
+}
+
+// City looks up a city by its IATA city code; its airports are ordered by traffic
+func (ix *Index) City(code string) (City, bool) {
+	c, ok := ix.cities[strings.ToUpper(code)]
+	if !ok {
+		return City{}, false
+	}
+	return *c, true
+}
+
+// Search returns up to limit airports matching q, best match first. Exact codes come first,
+// then name prefixes; ties go to the busier airport. Typos are only corrected when nothing
+// matches as typed.
+func (ix *Index) Search(q string, limit int) []Match {
+	qn := normalize(q)
+	if qn == "" || limit <= 0 {
+		return nil
+	}
+
+	best := make(map[int32]MatchKind)
+	add := func(id int32, kind MatchKind) {
+		if cur, ok := best[id]; !ok || kind < cur {
+			best[id] = kind
+		}
+	}
+
+	if len(qn) == 3 {
+		code := strings.ToUpper(qn)
+		if id, ok := ix.byCode[code]; ok {
+			add(id, MatchCode)
+		}
+		for _, id := range ix.byCity[code] {
+			add(id, MatchCityCode)
+		}
+	}
+
+	lo := sort.Search(len(ix.terms), func(i int) bool { return ix.terms[i].key >= qn })
+	for i := lo; i < len(ix.terms) && strings.HasPrefix(ix.terms[i].key, qn); i++ {
+		add(ix.terms[i].airport, ix.terms[i].kind)
+	}
+
+	if len(best) == 0 && utf8.RuneCountInString(qn) >= minFuzzyRunes {
+		ix.fuzzy([]rune(qn), add)
+	}
+
+	matches := make([]Match, 0, len(best))
+	for id, kind := range best {
+		matches = append(matches, Match{Airport: ix.airports[id], Kind: kind})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Airport.Passengers != b.Airport.Passengers {
+			return a.Airport.Passengers > b.Airport.Passengers
+		}
+		return a.Airport.Code < b.Airport.Code
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// fuzzy matches terms whose beginning is within a small edit distance of q. The first letter
+// has to be right; people rarely get it wrong and it rules out most terms cheaply.
+func (ix *Index) fuzzy(q []rune, add func(int32, MatchKind)) {
+	maxEdits := 1
+	if len(q) >= 8 {
+		maxEdits = 2
+	}
+
+	lastKey, lastHit := "", false
+	for _, t := range ix.terms {
+		if t.kind != MatchCity && t.kind != MatchCityAlias && t.kind != MatchName && t.kind != MatchAlias || t.runes[0] != q[0] {
+			continue
+		}
+		// terms are sorted, so repeated keys reuse the previous distance
+		if t.key != lastKey {
+			lastKey, lastHit = t.key, prefixDistance(q, t.runes, maxEdits) <= maxEdits
+		}
+		if lastHit {
+			add(t.airport, MatchFuzzy)
+		}
+	}
+}
+
+// prefixDistance is the Levenshtein distance between q and the closest prefix of key. It gives
+// up with limit+1 as soon as the distance is known to exceed limit.
+func prefixDistance(q, key []rune, limit int) int {
+	n := len(key)
+	if n > len(q)+limit {
+		n = len(q) + limit
+	}
+	prev := make([]int, n+1)
+	cur := make([]int, n+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(q); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= n; j++ {
+			cost := 1
+			if q[i-1] == key[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+
+	best := prev[0]
+	for _, d := range prev[1:] {
+		best = min(best, d)
+	}
+	return best
+}
+
+func (ix *Index) sortByTraffic(ids []int32) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ix.airports[ids[i]].Passengers > ix.airports[ids[j]].Passengers
+	})
+}
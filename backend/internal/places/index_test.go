@@ -0,0 +1,113 @@
+package places
+
+import (
+	"testing"
+)
+
+func testAirports() []Airport {
+	return []Airport{
+		{Code: "DEL", Name: "Indira Gandhi International Airport", CityCode: "DEL", City: "New Delhi", CountryCode: "IN", Country: "India", Lat: 28.5665, Lon: 77.1031, TimeZone: "Asia/Kolkata", Passengers: 72200, Aliases: []string{"Delhi", "Dilli"}},
+		{Code: "ZRH", Name: "Zürich Airport", CityCode: "ZRH", City: "Zürich", CountryCode: "CH", Country: "Switzerland", Lat: 47.4582, Lon: 8.5555, TimeZone: "Europe/Zurich", Passengers: 28900},
+		{Code: "FCO", Name: "Leonardo da Vinci Airport", CityCode: "ROM", City: "Rome", CountryCode: "IT", Country: "Italy", Lat: 41.8003, Lon: 12.2389, TimeZone: "Europe/Rome", Passengers: 40500, Aliases: []string{"Roma", "Fiumicino"}},
+		{Code: "CIA", Name: "Ciampino Airport", CityCode: "ROM", City: "Rome", CountryCode: "IT", Country: "Italy", Lat: 41.7994, Lon: 12.5949, TimeZone: "Europe/Rome", Passengers: 6000, Aliases: []string{"Roma"}},
+		{Code: "BER", Name: "Brandenburg Airport", CityCode: "BER", City: "Berlin", CountryCode: "DE", Country: "Germany", Lat: 52.3667, Lon: 13.5033, TimeZone: "Europe/Berlin", Passengers: 23000},
+		{Code: "BRN", Name: "Bern Airport", CityCode: "BRN", City: "Bern", CountryCode: "CH", Country: "Switzerland", Lat: 46.9141, Lon: 7.4997, TimeZone: "Europe/Zurich", Passengers: 100},
+		{Code: "FRA", Name: "Frankfurt Airport", CityCode: "FRA", City: "Frankfurt", CountryCode: "DE", Country: "Germany", Lat: 50.0379, Lon: 8.5622, TimeZone: "Europe/Berlin", Passengers: 59400},
+	}
+}
+
+type hit struct {
+	code string
+	kind MatchKind
+}
+
+func TestSearch(t *testing.T) {
+	ix := NewIndex(testAirports())
+
+	tests := []struct {
+		name  string
+		query string
+		want  []hit
+	}{
+		{name: "exact code", query: "zrh", want: []hit{{"ZRH", MatchCode}}},
+		{name: "code prefix", query: "zr", want: []hit{{"ZRH", MatchCodePrefix}}},
+		{name: "exact code beats a busier name prefix", query: "ber", want: []hit{{"BER", MatchCode}, {"BRN", MatchCity}}},
+		{name: "city code", query: "ROM", want: []hit{{"FCO", MatchCityCode}, {"CIA", MatchCityCode}}},
+		{name: "city alias ranks by traffic", query: "roma", want: []hit{{"FCO", MatchCityAlias}, {"CIA", MatchCityAlias}}},
+		{name: "airport alias", query: "fiumi", want: []hit{{"FCO", MatchAlias}}},
+		{name: "city beats alias", query: "delhi", want: []hit{{"DEL", MatchCity}}},
+		{name: "folded accent", query: "zurich", want: []hit{{"ZRH", MatchCity}}},
+		{name: "accent as typed", query: "ZÜRICH", want: []hit{{"ZRH", MatchCity}}},
+		{name: "later word of the city", query: "delh", want: []hit{{"DEL", MatchCity}}},
+		{name: "later word of the name", query: "gandhi", want: []hit{{"DEL", MatchName}}},
+		{name: "stop words start no phrase", query: "international", want: nil},
+		{name: "country", query: "switz", want: []hit{{"ZRH", MatchCountry}, {"BRN", MatchCountry}}},
+		{name: "prefix match suppresses typos", query: "berl", want: []hit{{"BER", MatchCity}}},
+		{name: "one typo", query: "berlim", want: []hit{{"BER", MatchFuzzy}}},
+		{name: "two typos in a short query", query: "bxrlxn", want: nil},
+		{name: "two typos in a long query", query: "frnakfurt", want: []hit{{"FRA", MatchFuzzy}}},
+		{name: "short queries are not corrected", query: "brl", want: nil},
+		{name: "first letter must match", query: "xerlin", want: nil},
+		{name: "blank", query: " - ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []hit
+			for _, m := range ix.Search(tt.query, 10) {
+				got = append(got, hit{m.Airport.Code, m.Kind})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	ix := NewIndex(testAirports())
+	if got := ix.Search("rom", 1); len(got) != 1 || got[0].Airport.Code != "FCO" {
+		t.Fatalf("got %+v, want only FCO", got)
+	}
+	if got := ix.Search("rom", 0); got != nil {
+		t.Fatalf("got %+v for limit 0", got)
+	}
+}
+
+func TestCityOrdersAirportsByTraffic(t *testing.T) {
+	ix := NewIndex(testAirports())
+	city, ok := ix.City("rom")
+	if !ok {
+		t.Fatal("ROM not found")
+	}
+	if city.Name != "Rome" || len(city.Airports) != 2 || city.Airports[0] != "FCO" || city.Airports[1] != "CIA" {
+		t.Fatalf("got %+v", city)
+	}
+}
+
+func TestPrefixDistance(t *testing.T) {
+	tests := []struct {
+		q, key string
+		limit  int
+		want   int
+	}{
+		{q: "delhi", key: "delhi", limit: 1, want: 0},
+		{q: "del", key: "delhi", limit: 1, want: 0},
+		{q: "dlhi", key: "delhi", limit: 1, want: 1},
+		{q: "delhx", key: "delhi", limit: 1, want: 1},
+		{q: "dxlhx", key: "delhi", limit: 1, want: 2},
+		{q: "dxlhx", key: "delhi", limit: 2, want: 2},
+		{q: "delhi", key: "del", limit: 2, want: 2},
+		{q: "abcd", key: "wxyz", limit: 1, want: 2},
+	}
+	for _, tt := range tests {
+		got := prefixDistance([]rune(tt.q), []rune(tt.key), tt.limit)
+		if got != tt.want {
+			t.Errorf("prefixDistance(%q, %q, %d) = %d, want %d", tt.q, tt.key, tt.limit, got, tt.want)
+		}
+	}
+}
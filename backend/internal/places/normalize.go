@@ -0,0 +1,74 @@
+package places
+
+import (
+	"strings"
+	"unicode"
+)
+
+// folds accented latin letters so "zurich" finds Zürich and "sao" finds São Paulo
+var foldReplacer = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"ç", "c", "ć", "c", "č", "c",
+	"ď", "d", "đ", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ě", "e", "ę", "e",
+	"ğ", "g",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i", "ı", "i", "i̇", "i",
+	"ł", "l",
+	"ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ő", "o",
+	"ř", "r",
+	"ś", "s", "š", "s", "ş", "s", "ș", "s",
+	"ť", "t", "ţ", "t", "ț", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ů", "u", "ű", "u", "ū", "u",
+	"ý", "y", "ÿ", "y",
+	"ź", "z", "ż", "z", "ž", "z",
+	"ß", "ss", "æ", "ae", "œ", "oe", "ё", "е",
+)
+
+// normalize lowercases, folds accents and reduces punctuation to single spaces
+func normalize(s string) string {
+	s = foldReplacer.Replace(strings.ToLower(s))
+
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// words that say nothing about which airport is meant
+var stopWords = map[string]bool{
+	"airport": true, "international": true, "intl": true, "of": true, "the": true, "de": true,
+	"da": true, "el": true, "la": true, "i": true,
+}
+
+// phrases returns the normalized text and every suffix of it starting at a meaningful word, so
+// "new delhi" is found by both "new d" and "delh"
+func phrases(s string) []string {
+	n := normalize(s)
+	if n == "" {
+		return nil
+	}
+	out := []string{n}
+	for i := 0; i < len(n); i++ {
+		if n[i] != ' ' {
+			continue
+		}
+		rest := n[i+1:]
+		word, _, _ := strings.Cut(rest, " ")
+		if !stopWords[word] {
+			out = append(out, rest)
+		}
+	}
+	return out
+}
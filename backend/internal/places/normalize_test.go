@@ -0,0 +1,41 @@
+package places
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Zürich":                 "zurich",
+		"  São Paulo–Guarulhos ": "sao paulo guarulhos",
+		"St. Petersburg":         "st petersburg",
+		"Kraków-Balice":          "krakow balice",
+		"Москва":                 "москва",
+		"":                       "",
+		"--":                     "",
+	}
+	for in, want := range tests {
+		if got := normalize(in); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPhrases(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "New Delhi", want: []string{"new delhi", "delhi"}},
+		{in: "Indira Gandhi International Airport", want: []string{"indira gandhi international airport", "gandhi international airport"}},
+		{in: "Aeropuerto de la Ciudad", want: []string{"aeropuerto de la ciudad", "ciudad"}},
+		{in: "Zürich", want: []string{"zurich"}},
+		{in: " ", want: nil},
+	}
+	for _, tt := range tests {
+		if got := phrases(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("phrases(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
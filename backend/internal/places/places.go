@@ -0,0 +1,163 @@
+// Package places is the airport, city and country reference data behind autocomplete and
+// itinerary enrichment. The embedded dataset keeps it working offline; a file generated from
+// the Travelpayouts data dumps can replace it.
+package places
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//go:embed airports.csv
+var defaultAirports []byte
+
+// Airport is one airport with the city and country it serves. Passengers is annual traffic in
+// thousands and only ranks search matches; Aliases hold other spellings and transliterations.
+type Airport struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	CityCode    string   `json:"city_code"`
+	City        string   `json:"city"`
+	CountryCode string   `json:"country_code"`
+	Country     string   `json:"country"`
+	Lat         float64  `json:"lat"`
+	Lon         float64  `json:"lon"`
+	TimeZone    string   `json:"time_zone"`
+	Passengers  int      `json:"passengers"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// City groups the airports sharing an IATA city code
+type City struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	CountryCode string   `json:"country_code"`
+	Country     string   `json:"country"`
+	Airports    []string `json:"airports"`
+}
+
+var csvHeader = []string{"iata", "name", "city_code", "city", "country_code", "country", "lat", "lon", "tz", "passengers", "aliases"}
+
+// DefaultAirports returns the embedded dataset
+func DefaultAirports() []Airport {
+	airports, err := ReadCSV(bytes.NewReader(defaultAirports))
+	if err != nil {
+		panic(fmt.Errorf("embedded airports: %w", err))
+	}
+	return airports
+}
+
+// LoadFile reads a dataset from a .csv file in the embedded format or a .json array of Airport
+func LoadFile(path string) ([]Airport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var airports []Airport
+		if err := json.NewDecoder(f).Decode(&airports); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return airports, validate(airports)
+	}
+	return ReadCSV(f)
+}
+
+// ReadCSV reads airports in the format of airports.csv; aliases are separated by |
+func ReadCSV(r io.Reader) ([]Airport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if strings.Join(header, ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("unexpected header %q", strings.Join(header, ","))
+	}
+
+	var airports []Airport
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		a := Airport{
+			Code:        rec[0],
+			Name:        rec[1],
+			CityCode:    rec[2],
+			City:        rec[3],
+			CountryCode: rec[4],
+			Country:     rec[5],
+			TimeZone:    rec[8],
+		}
+		if a.Lat, err = strconv.ParseFloat(rec[6], 64); err != nil {
+			return nil, fmt.Errorf("%s: lat: %w", a.Code, err)
+		}
+		if a.Lon, err = strconv.ParseFloat(rec[7], 64); err != nil {
+			return nil, fmt.Errorf("%s: lon: %w", a.Code, err)
+		}
+		if rec[9] != "" {
+			if a.Passengers, err = strconv.Atoi(rec[9]); err != nil {
+				return nil, fmt.Errorf("%s: passengers: %w", a.Code, err)
+			}
+		}
+		if rec[10] != "" {
+			a.Aliases = strings.Split(rec[10], "|")
+		}
+		airports = append(airports, a)
+	}
+	return airports, validate(airports)
+}
+
+// WriteCSV writes airports in the format ReadCSV reads
+func WriteCSV(w io.Writer, airports []Airport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, a := range airports {
+		err := cw.Write([]string{
+			a.Code, a.Name, a.CityCode, a.City, a.CountryCode, a.Country,
+			strconv.FormatFloat(a.Lat, 'f', 4, 64), strconv.FormatFloat(a.Lon, 'f', 4, 64),
+			a.TimeZone, strconv.Itoa(a.Passengers), strings.Join(a.Aliases, "|"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func validate(airports []Airport) error {
+	if len(airports) == 0 {
+		return errors.New("no airports")
+	}
+	seen := make(map[string]bool, len(airports))
+	for i, a := range airports {
+		if len(a.Code) != 3 || len(a.CityCode) != 3 {
+			return fmt.Errorf("airport %d: invalid code %q or city code %q", i, a.Code, a.CityCode)
+		}
+		if seen[a.Code] {
+			return fmt.Errorf("duplicate airport %s", a.Code)
+		}
+		seen[a.Code] = true
+	}
+	return nil
+}
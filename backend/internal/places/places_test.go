@@ -0,0 +1,42 @@
+package places
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	for name, airports := range map[string][]Airport{"fixture": testAirports(), "embedded": DefaultAirports()} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, airports); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadCSV(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, airports) {
+				t.Fatalf("round trip changed the airports:\n got %+v\nwant %+v", got, airports)
+			}
+		})
+	}
+}
+
+func TestReadCSVRejects(t *testing.T) {
+	header := strings.Join(csvHeader, ",") + "\n"
+	tests := map[string]string{
+		"wrong header":   "code,name\n",
+		"no airports":    header,
+		"bad latitude":   header + "DEL,Delhi,DEL,Delhi,IN,India,north,77.1,Asia/Kolkata,1,\n",
+		"short code":     header + "DE,Delhi,DEL,Delhi,IN,India,28.5,77.1,Asia/Kolkata,1,\n",
+		"duplicate code": header + "DEL,Delhi,DEL,Delhi,IN,India,28.5,77.1,Asia/Kolkata,1,\nDEL,Delhi,DEL,Delhi,IN,India,28.5,77.1,Asia/Kolkata,1,\n",
+	}
+	for name, in := range tests {
+		if _, err := ReadCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package places
+
+// Suggestion is one autocomplete entry: a single airport, or a city with all of its airports
+type Suggestion struct {
+	Airport  *Airport
+	City     *City
+	Airports []Airport
+}
+
+// Suggest is Search with multi-airport cities grouped. When a query names a city, such as
+// "london" or "LON", the city comes first with its airports under it so the whole city can be
+// searched; an airport found by its own code or name stays a single entry. limit counts entries,
+// so matches are grouped first and a city takes one entry however many of its airports matched.
+func (ix *Index) Suggest(q string, limit int) []Suggestion {
+	if limit <= 0 {
+		return nil
+	}
+	matches := ix.Search(q, ix.Len())
+
+	out := make([]Suggestion, 0, min(limit, len(matches)))
+	grouped := make(map[string]bool)
+	for _, m := range matches {
+		if len(out) == limit {
+			break
+		}
+		a := m.Airport
+		if grouped[a.CityCode] {
+			continue
+		}
+
+		ids := ix.byCity[a.CityCode]
+		if len(ids) > 1 && namesCity(m.Kind) {
+			grouped[a.CityCode] = true
+			city := *ix.cities[a.CityCode]
+			s := Suggestion{City: &city, Airports: make([]Airport, 0, len(ids))}
+			for _, id := range ids {
+				s.Airports = append(s.Airports, ix.airports[id])
+			}
+			out = append(out, s)
+			continue
+		}
+
+		out = append(out, Suggestion{Airport: &a})
+	}
+	return out
+}
+
+// namesCity reports whether a match came from the city rather than the airport itself
+func namesCity(kind MatchKind) bool {
+	switch kind {
+	case MatchCityCode, MatchCity, MatchCityAlias, MatchFuzzy:
+		return true
+	}
+	return false
+}
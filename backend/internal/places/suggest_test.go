@@ -0,0 +1,59 @@
+package places
+
+import (
+	"testing"
+)
+
+func TestSuggestGroupsCityAirports(t *testing.T) {
+	ix := NewIndex(londonAirports())
+
+	for _, q := range []string{"london", "LON"} {
+		t.Run(q, func(t *testing.T) {
+			got := ix.Suggest(q, 1)
+			if len(got) != 1 || got[0].City == nil {
+				t.Fatalf("got %+v, want the city of London", got)
+			}
+			var airports []string
+			for _, a := range got[0].Airports {
+				airports = append(airports, a.Code)
+			}
+			want := []string{"LHR", "LGW", "STN", "LTN", "LCY"}
+			if len(airports) != len(want) {
+				t.Fatalf("airports %v, want %v", airports, want)
+			}
+			for i := range want {
+				if airports[i] != want[i] {
+					t.Fatalf("airports %v, want %v", airports, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestKeepsAirportMatchesSingle(t *testing.T) {
+	ix := NewIndex(londonAirports())
+	for _, q := range []string{"lhr", "heathrow"} {
+		got := ix.Suggest(q, 5)
+		if len(got) != 1 || got[0].Airport == nil || got[0].Airport.Code != "LHR" {
+			t.Fatalf("Suggest(%q) = %+v, want LHR alone", q, got)
+		}
+	}
+}
+
+func TestSuggestFillsLimitAfterGrouping(t *testing.T) {
+	ix := NewIndex(londonAirports())
+
+	got := ix.Suggest("lon", 2)
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if got[0].City == nil || got[0].City.Code != "LON" || len(got[0].Airports) != 5 {
+		t.Fatalf("first entry %+v, want London with its five airports", got[0])
+	}
+	if got[1].Airport == nil || got[1].Airport.Code != "LDB" {
+		t.Fatalf("second entry %+v, want LDB", got[1])
+	}
+	if got := ix.Suggest("lon", 0); got != nil {
+		t.Fatalf("got %+v for limit 0", got)
+	}
+}
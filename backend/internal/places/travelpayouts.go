@@ -0,0 +1,129 @@
+package places
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TravelpayoutsDumpURL locates the public data dumps; %s is airports, cities or countries
+const TravelpayoutsDumpURL = "https://api.travelpayouts.com/data/en/%s.json"
+
+type tpAirport struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	CityCode    string `json:"city_code"`
+	CountryCode string `json:"country_code"`
+	TimeZone    string `json:"time_zone"`
+	Flightable  bool   `json:"flightable"`
+	IATAType    string `json:"iata_type"`
+	Coordinates struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"coordinates"`
+}
+
+type tpCity struct {
+	Code             string            `json:"code"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+}
+
+type tpCountry struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// ReadTravelpayouts builds a dataset from the airports, cities and countries dumps, keeping
+// flightable airports only. City name translations become aliases. Traffic is not part of the
+// dumps and is carried over, with any aliases, from base for airports it already has.
+func ReadTravelpayouts(airports, cities, countries io.Reader, base []Airport) ([]Airport, error) {
+	var (
+		tpAirports  []tpAirport
+		tpCities    []tpCity
+		tpCountries []tpCountry
+	)
+	if err := json.NewDecoder(airports).Decode(&tpAirports); err != nil {
+		return nil, fmt.Errorf("airports: %w", err)
+	}
+	if err := json.NewDecoder(cities).Decode(&tpCities); err != nil {
+		return nil, fmt.Errorf("cities: %w", err)
+	}
+	if err := json.NewDecoder(countries).Decode(&tpCountries); err != nil {
+		return nil, fmt.Errorf("countries: %w", err)
+	}
+
+	cityByCode := make(map[string]tpCity, len(tpCities))
+	for _, c := range tpCities {
+		cityByCode[c.Code] = c
+	}
+	countryName := make(map[string]string, len(tpCountries))
+	for _, c := range tpCountries {
+		countryName[c.Code] = c.Name
+	}
+	known := make(map[string]Airport, len(base))
+	for _, a := range base {
+		known[a.Code] = a
+	}
+
+	out := make([]Airport, 0, len(tpAirports))
+	for _, t := range tpAirports {
+		if !t.Flightable || t.IATAType != "airport" || len(t.Code) != 3 || len(t.CityCode) != 3 {
+			continue
+		}
+		city := cityByCode[t.CityCode]
+		a := Airport{
+			Code:        t.Code,
+			Name:        t.Name,
+			CityCode:    t.CityCode,
+			City:        city.Name,
+			CountryCode: t.CountryCode,
+			Country:     countryName[t.CountryCode],
+			Lat:         t.Coordinates.Lat,
+			Lon:         t.Coordinates.Lon,
+			TimeZone:    t.TimeZone,
+		}
+		if a.City == "" {
+			a.City = a.CityCode
+		}
+		if a.Name == "" {
+			a.Name = a.City
+		}
+
+		prev := known[a.Code]
+		a.Passengers = prev.Passengers
+		a.Aliases = mergeAliases(a, prev.Aliases, city.NameTranslations)
+		out = append(out, a)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, validate(out)
+}
+
+func mergeAliases(a Airport, aliases []string, translations map[string]string) []string {
+	seen := map[string]bool{normalize(a.City): true, normalize(a.Name): true}
+	var out []string
+	add := func(s string) {
+		n := normalize(s)
+		if n == "" || seen[n] || strings.ContainsAny(s, "|\n") {
+			return
+		}
+		seen[n] = true
+		out = append(out, s)
+	}
+
+	for _, s := range aliases {
+		add(s)
+	}
+	langs := make([]string, 0, len(translations))
+	for lang := range translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		add(translations[lang])
+	}
+	return out
+}
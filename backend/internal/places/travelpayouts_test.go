@@ -0,0 +1,45 @@
+package places
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTravelpayouts(t *testing.T) {
+	airports := `[
+		{"code": "DEL", "name": "Indira Gandhi International Airport", "city_code": "DEL", "country_code": "IN", "time_zone": "Asia/Kolkata", "flightable": true, "iata_type": "airport", "coordinates": {"lat": 28.5665, "lon": 77.1031}},
+		{"code": "BOM", "name": "", "city_code": "BOM", "country_code": "IN", "time_zone": "Asia/Kolkata", "flightable": true, "iata_type": "airport", "coordinates": {"lat": 19.0887, "lon": 72.8679}},
+		{"code": "NZM", "name": "Hazrat Nizamuddin", "city_code": "DEL", "country_code": "IN", "flightable": true, "iata_type": "railway"},
+		{"code": "XYZ", "name": "Closed Airfield", "city_code": "DEL", "country_code": "IN", "flightable": false, "iata_type": "airport"}
+	]`
+	cities := `[
+		{"code": "DEL", "name": "New Delhi", "name_translations": {"en": "New Delhi", "ru": "Нью-Дели", "de": "Neu-Delhi"}},
+		{"code": "BOM", "name": "Mumbai", "name_translations": {"en": "Mumbai"}}
+	]`
+	countries := `[{"code": "IN", "name": "India"}]`
+	base := []Airport{{Code: "DEL", Passengers: 72200, Aliases: []string{"Dilli", "Neu-Delhi"}}}
+
+	got, err := ReadTravelpayouts(strings.NewReader(airports), strings.NewReader(cities), strings.NewReader(countries), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Airport{
+		{Code: "BOM", Name: "Mumbai", CityCode: "BOM", City: "Mumbai", CountryCode: "IN", Country: "India", Lat: 19.0887, Lon: 72.8679, TimeZone: "Asia/Kolkata"},
+		{
+			Code: "DEL", Name: "Indira Gandhi International Airport", CityCode: "DEL", City: "New Delhi", CountryCode: "IN", Country: "India",
+			Lat: 28.5665, Lon: 77.1031, TimeZone: "Asia/Kolkata", Passengers: 72200, Aliases: []string{"Dilli", "Neu-Delhi", "Нью-Дели"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadTravelpayoutsRejectsBadJSON(t *testing.T) {
+	_, err := ReadTravelpayouts(strings.NewReader(`[]`), strings.NewReader(`{`), strings.NewReader(`[]`), nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "cities:") {
+		t.Fatalf("got %v, want a cities error", err)
+	}
+}
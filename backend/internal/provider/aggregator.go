@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"stopover.backend/internal/models"
+)
+
+const DefaultProviderTimeout = 30 * time.Second
+
+// Aggregator fans a search out to every registered provider concurrently and merges the itineraries
+type Aggregator struct {
+	mu        sync.RWMutex
+	providers []FlightProvider
+	timeout   time.Duration
+}
+
+func NewAggregator(timeout time.Duration, providers ...FlightProvider) *Aggregator {
+	if timeout <= 0 {
+		timeout = DefaultProviderTimeout
+	}
+	return &Aggregator{
+		providers: providers,
+		timeout:   timeout,
+	}
+}
+
+// Register adds a provider to the fan-out
+func (a *Aggregator) Register(p FlightProvider) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.providers = append(a.providers, p)
+}
+
+// Search queries every provider and merges their itineraries. A failing provider is reported in the
+// outcome list but does not fail the search unless every provider fails.
+func (a *Aggregator) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	a.mu.RLock()
+	providers := make([]FlightProvider, len(a.providers))
+	copy(providers, a.providers)
+	a.mu.RUnlock()
+
+	if len(providers) == 0 {
+		return nil, errors.New("no flight providers registered")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	results := make([]*models.FlightSearchResult, len(providers))
+	outcomes := make([]models.ProviderOutcome, len(providers))
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p FlightProvider) {
+			defer wg.Done()
+			outcomes[i].Name = p.Name()
+
+			res, err := p.Search(ctx, query)
+			if err != nil {
+				log.Printf("[Aggregator] provider %s failed: %v", p.Name(), err)
+				outcomes[i].Error = err.Error()
+				return
+			}
+			results[i] = res
+			outcomes[i].Itineraries = len(res.Itineraries)
+		}(i, p)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, o := range outcomes {
+		if o.Error != "" {
+			failed++
+		}
+	}
+	if failed == len(providers) {
+		return nil, errors.New("all flight providers failed")
+	}
+
+	merged := MergeResults(results...)
+	merged.Providers = outcomes
+	return merged, nil
+}
+
+// MergeResults combines itineraries from several results. Itineraries with the same id are
+// collapsed into one, keeping the cheapest fare per provider and gate. The result is ordered
+// cheapest first.
+func MergeResults(results ...*models.FlightSearchResult) *models.FlightSearchResult {
+	out := &models.FlightSearchResult{
+		Itineraries: make([]models.Itinerary, 0),
+	}
+	index := make(map[string]int)
+
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+		if out.Currency == "" {
+			out.Currency = res.Currency
+		}
+		for _, it := range res.Itineraries {
+			i, ok := index[it.Id]
+			if !ok {
+				index[it.Id] = len(out.Itineraries)
+				it.Fares = mergeFares(nil, it.Fares)
+				out.Itineraries = append(out.Itineraries, it)
+				continue
+			}
+			out.Itineraries[i].Fares = mergeFares(out.Itineraries[i].Fares, it.Fares)
+		}
+	}
+
+	// itineraries without a priced fare have a zero best fare, which Cheaper puts last
+	sort.SliceStable(out.Itineraries, func(i, j int) bool {
+		a, _ := out.Itineraries[i].BestFare()
+		b, _ := out.Itineraries[j].BestFare()
+		return a.Cheaper(b)
+	})
+	return out
+}
+
+// mergeFares adds fares to an existing list keeping only the cheapest per provider and gate
+func mergeFares(existing, fares []models.Fare) []models.Fare {
+	merged := make([]models.Fare, 0, len(existing)+len(fares))
+	index := make(map[string]int)
+	for _, set := range [][]models.Fare{existing, fares} {
+		for _, f := range set {
+			key := f.Provider + "|" + f.Gate
+			if i, ok := index[key]; ok {
+				if f.Cheaper(merged[i]) {
+					merged[i] = f
+				}
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, f)
+		}
+	}
+	sortFares(merged)
+	return merged
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/aviasales"
+)
+
+// AviasalesProvider adapts the Travelpayouts flight search API to FlightProvider
+type AviasalesProvider struct {
+	api          aviasales.FlightIntegrationAPI
+	maxAttempts  int
+	pollInterval time.Duration
+}
+
+func NewAviasalesProvider(api aviasales.FlightIntegrationAPI, maxAttempts int, pollInterval time.Duration) FlightProvider {
+	return &AviasalesProvider{
+		api:          api,
+		maxAttempts:  maxAttempts,
+		pollInterval: pollInterval,
+	}
+}
+
+func (p *AviasalesProvider) Name() string {
+	return "aviasales"
+}
+
+// Search starts an Aviasales search, polls it to completion and maps the proposals to itineraries
+func (p *AviasalesProvider) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	initResp, err := p.api.InitSearch(ctx, ToAviasalesRequest(query))
+	if err != nil {
+		return nil, fmt.Errorf("init search: %w", err)
+	}
+
+	results, err := p.api.GetSearchResultsWithPolling(ctx, initResp.SearchID, p.maxAttempts, p.pollInterval, aviasales.DefaultPipeline...)
+	if err != nil {
+		return nil, fmt.Errorf("poll results: %w", err)
+	}
+
+	return MapAviasalesResults(p.Name(), results), nil
+}
+
+// ToAviasalesRequest converts a neutral query into an Aviasales search request.
+// Marker, host and signature are filled in by the client.
+func ToAviasalesRequest(query models.FlightQuery) aviasales.FlightSearchRequest {
+	segments := make([]aviasales.Segment, 0, len(query.Segments))
+	for _, s := range query.Segments {
+		segments = append(segments, aviasales.Segment{
+			Origin:      s.Origin,
+			Destination: s.Destination,
+			Date:        s.Date,
+		})
+	}
+
+	return aviasales.FlightSearchRequest{
+		UserIP:    query.UserIP,
+		Locale:    query.Locale,
+		TripClass: query.TripClass,
+		Passengers: aviasales.PassengerInfo{
+			Adults:   query.Adults,
+			Children: query.Children,
+			Infants:  query.Infants,
+		},
+		Segments: segments,
+	}
+}
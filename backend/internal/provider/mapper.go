@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/money"
+)
+
+const aviasalesDateTimeLayout = "2006-01-02 15:04"
+
+var (
+	locationsMu sync.RWMutex
+	locations   = map[string]*time.Location{}
+)
+
+// MapAviasalesResults converts an Aviasales result set into domain itineraries.
+// Flight times are parsed in the time zone of their airport taken from the result's
+// airport metadata; airports without a known zone fall back to UTC. The airport and airline
+// metadata the supplier sent is attached to every flight.
+func MapAviasalesResults(providerName string, res *aviasales.FlightSearchResponseWrapper) *models.FlightSearchResult {
+	out := &models.FlightSearchResult{
+		Itineraries: make([]models.Itinerary, 0, len(res.Proposals)),
+		Currency:    strings.ToUpper(res.Currency),
+	}
+	for _, p := range res.Proposals {
+		it := MapAviasalesProposal(providerName, p, res.Airports)
+		attachMetadata(&it, res.Airports, res.Airlines)
+		// booking a fare needs the upstream search it was found in
+		for i := range it.Fares {
+			it.Fares[i].SearchID = res.SearchID
+		}
+		out.Itineraries = append(out.Itineraries, it)
+	}
+	return out
+}
+
+// MapAviasalesProposal converts one proposal and its agency terms into an itinerary
+func MapAviasalesProposal(providerName string, p aviasales.Proposal, airports map[string]aviasales.Airport) models.Itinerary {
+	it := models.Itinerary{
+		Legs:            make([]models.Leg, 0, len(p.Segment)),
+		Carriers:        p.Carriers,
+		IsDirect:        p.Direct(),
+		DurationMinutes: p.TotalDuration,
+		Fares:           make([]models.Fare, 0, len(p.Terms)),
+	}
+
+	for _, seg := range p.Segment {
+		it.Legs = append(it.Legs, mapLeg(seg, airports))
+	}
+	it.Id = ItineraryID(it)
+
+	for gate, term := range p.Terms {
+		it.Fares = append(it.Fares, models.Fare{
+			Provider:     providerName,
+			Gate:         gate,
+			Currency:     strings.ToUpper(term.Currency),
+			Price:        money.FromFloat(term.Price),
+			UnifiedPrice: money.FromFloat(term.UnifiedPrice),
+			BookingToken: term.URL.String(),
+		})
+	}
+	sortFares(it.Fares)
+	return it
+}
+
+func mapLeg(seg aviasales.FlightSegment, airports map[string]aviasales.Airport) models.Leg {
+	leg := models.Leg{
+		Segments: make([]models.FlightSegment, 0, len(seg.Flight)),
+	}
+	for _, f := range seg.Flight {
+		leg.Segments = append(leg.Segments, models.FlightSegment{
+			Origin:           f.Departure,
+			Destination:      f.Arrival,
+			Departure:        parseLocalTime(f.DepartureDate, f.DepartureTime, airports[f.Departure].TimeZone),
+			Arrival:          parseLocalTime(f.ArrivalDate, f.ArrivalTime, airports[f.Arrival].TimeZone),
+			MarketingCarrier: f.MarketingCarrier,
+			OperatingCarrier: f.OperatingCarrier,
+			FlightNumber:     f.Number,
+			Aircraft:         f.Aircraft,
+			TripClass:        f.TripClass,
+			DurationMinutes:  f.Duration,
+		})
+	}
+
+	if n := len(leg.Segments); n > 0 {
+		first, last := leg.Segments[0], leg.Segments[n-1]
+		leg.Origin = first.Origin
+		leg.Destination = last.Destination
+		leg.Departure = first.Departure
+		leg.Arrival = last.Arrival
+		leg.Stops = n - 1
+
+		// elapsed time is only meaningful when both ends were parsed in their real zones
+		if airports[first.Origin].TimeZone != "" && airports[last.Destination].TimeZone != "" {
+			leg.DurationMinutes = int(last.Arrival.Sub(first.Departure).Minutes())
+		} else {
+			for _, s := range leg.Segments {
+				leg.DurationMinutes += s.DurationMinutes
+			}
+		}
+	}
+	return leg
+}
+
+// attachMetadata fills the info fields of every flight from the supplier's metadata. Entries
+// named only by their code are placeholders and are left for enrichment.
+func attachMetadata(it *models.Itinerary, airports map[string]aviasales.Airport, airlines map[string]aviasales.Airline) {
+	airport := func(code string) *models.AirportInfo {
+		a, ok := airports[code]
+		if !ok || a.Name == "" || a.Name == code {
+			return nil
+		}
+		return &models.AirportInfo{Code: code, Name: a.Name, City: a.City, CityCode: a.CityCode, Country: a.Country, TimeZone: a.TimeZone}
+	}
+	airline := func(code string) *models.AirlineInfo {
+		a, ok := airlines[code]
+		if !ok || a.Name == "" || a.Name == code {
+			return nil
+		}
+		return &models.AirlineInfo{Code: code, Name: a.Name}
+	}
+
+	for i := range it.Legs {
+		for j := range it.Legs[i].Segments {
+			s := &it.Legs[i].Segments[j]
+			s.OriginAirport = airport(s.Origin)
+			s.DestinationAirport = airport(s.Destination)
+			s.MarketingAirline = airline(s.MarketingCarrier)
+			s.OperatingAirline = airline(s.OperatingCarrier)
+		}
+	}
+}
+
+// parseLocalTime reads an Aviasales date and time pair in the given IANA zone
+func parseLocalTime(date, clock, zone string) time.Time {
+	if date == "" || clock == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(aviasalesDateTimeLayout, date+" "+clock, loadLocation(zone))
+	if err != nil {
+		log.Printf("[Mapper] invalid flight time %q %q: %v", date, clock, err)
+		return time.Time{}
+	}
+	return t
+}
+
+func loadLocation(zone string) *time.Location {
+	if zone == "" {
+		return time.UTC
+	}
+
+	locationsMu.RLock()
+	loc, ok := locations[zone]
+	locationsMu.RUnlock()
+	if ok {
+		return loc
+	}
+
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		log.Printf("[Mapper] unknown time zone %q, using UTC: %v", zone, err)
+		loc = time.UTC
+	}
+
+	locationsMu.Lock()
+	locations[zone] = loc
+	locationsMu.Unlock()
+	return loc
+}
+
+// ItineraryID identifies an itinerary by its flights so the same trip sold by
+// different providers collapses to one id
+func ItineraryID(it models.Itinerary) string {
+	var b strings.Builder
+	for i, leg := range it.Legs {
+		if i > 0 {
+			b.WriteString("/")
+		}
+		for j, s := range leg.Segments {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(s.MarketingCarrier + s.FlightNumber + "@" + s.Departure.UTC().Format("200601021504"))
+		}
+	}
+	return b.String()
+}
+
+func sortFares(fares []models.Fare) {
+	sort.SliceStable(fares, func(i, j int) bool {
+		return fares[i].Cheaper(fares[j])
+	})
+}
@@ -0,0 +1,21 @@
+package provider
+
+import (
+	"context"
+
+	"stopover.backend/internal/models"
+)
+
+// FlightProvider is a flight supplier that can answer a provider-neutral search.
+// Implementations return itineraries in the domain model; the Providers field is left
+// for the aggregator to fill in.
+type FlightProvider interface {
+	Name() string
+	Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error)
+}
+
+// Searcher answers a search across one or more providers; the Aggregator and its cached
+// wrapper both satisfy it
+type Searcher interface {
+	Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error)
+}
@@ -0,0 +1,396 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// alertColumns is the column list every alert query returns, read back by collectAlerts
+const alertColumns = `a.alert_id, a.user_id, a.origin, a.destination, a.depart_from, a.depart_to, a.stay_days,
+	a.adults, a.trip_class, a.currency, a.threshold_minor, a.channel, coalesce(a.target, ''),
+	coalesce(a.user_ip, ''), a.active, a.last_price_minor, a.last_checked_at, a.next_check_at,
+	a.notified_price_minor, a.notified_at, a.created_at, a.updated_at`
+
+func (db *DbClient) CreatePriceAlert(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error) {
+	query := `insert into tbl_mst_price_alert as a(user_id,origin,destination,depart_from,depart_to,stay_days,
+		adults,trip_class,currency,threshold_minor,channel,target,user_ip,active)
+	values(@user_id,@origin,@destination,@depart_from,@depart_to,@stay_days,
+		@adults,@trip_class,@currency,@threshold_minor,@channel,@target,@user_ip,@active)
+	returning ` + alertColumns
+	args := alertArgs(alert)
+	args["user_id"] = alert.UserId
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("CreatePriceAlert QUERY failed: " + err.Error())
+		return nil, err
+	}
+	return collectOneAlert(rows, "CreatePriceAlert")
+}
+
+// UpdatePriceAlert replaces the settings of a user's alert. The alert is due for a check straight
+// away and its last notification is forgotten, since the old one may not meet the new threshold.
+func (db *DbClient) UpdatePriceAlert(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error) {
+	query := `update tbl_mst_price_alert as a set origin=@origin, destination=@destination, depart_from=@depart_from,
+		depart_to=@depart_to, stay_days=@stay_days, adults=@adults, trip_class=@trip_class, currency=@currency,
+		threshold_minor=@threshold_minor, channel=@channel, target=@target, user_ip=@user_ip, active=@active,
+		last_price_minor=null, notified_price_minor=null, notified_at=null, next_check_at=now(), updated_at=now()
+	where a.alert_id=@alert_id and a.user_id=@user_id
+	returning ` + alertColumns
+	args := alertArgs(alert)
+	args["alert_id"] = alert.AlertId
+	args["user_id"] = alert.UserId
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("UpdatePriceAlert QUERY failed: " + err.Error())
+		return nil, err
+	}
+	return collectOneAlert(rows, "UpdatePriceAlert")
+}
+
+// GetPriceAlert returns pgx.ErrNoRows when the user has no alert with the id
+func (db *DbClient) GetPriceAlert(ctx context.Context, userId, alertId int64) (*models.PriceAlert, error) {
+	query := `select ` + alertColumns + ` from tbl_mst_price_alert a where a.alert_id=@alert_id and a.user_id=@user_id`
+	args := pgx.NamedArgs{
+		"alert_id": alertId,
+		"user_id":  userId,
+	}
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("GetPriceAlert QUERY failed: " + err.Error())
+		return nil, err
+	}
+	return collectOneAlert(rows, "GetPriceAlert")
+}
+
+func (db *DbClient) ListPriceAlerts(ctx context.Context, userId int64) ([]*models.PriceAlert, error) {
+	query := `select ` + alertColumns + ` from tbl_mst_price_alert a where a.user_id=@user_id order by a.alert_id`
+	args := pgx.NamedArgs{
+		"user_id": userId,
+	}
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("ListPriceAlerts QUERY failed: " + err.Error())
+		return nil, err
+	}
+	return collectAlerts(rows, "ListPriceAlerts")
+}
+
+func (db *DbClient) CountPriceAlerts(ctx context.Context, userId int64) (int, error) {
+	query := `select count(*) from tbl_mst_price_alert where user_id=@user_id`
+	args := pgx.NamedArgs{
+		"user_id": userId,
+	}
+
+	var count int
+	if err := db.Conn.QueryRow(ctx, query, args).Scan(&count); err != nil {
+		log.Println("CountPriceAlerts QUERY failed: " + err.Error())
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeletePriceAlert reports whether the user had an alert with the id; its history goes with it
+func (db *DbClient) DeletePriceAlert(ctx context.Context, userId, alertId int64) (bool, error) {
+	query := `delete from tbl_mst_price_alert where alert_id=@alert_id and user_id=@user_id`
+	args := pgx.NamedArgs{
+		"alert_id": alertId,
+		"user_id":  userId,
+	}
+
+	resp, err := db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("DeletePriceAlert QUERY failed: " + err.Error())
+		return false, err
+	}
+	return resp.RowsAffected() > 0, nil
+}
+
+// ClaimDueAlerts returns up to limit active alerts whose check is due and moves their next check to
+// nextCheckAt in the same statement. Rows locked by another claim are skipped, so concurrent workers
+// never receive the same alert and an alert whose check crashes is not retried before nextCheckAt.
+func (db *DbClient) ClaimDueAlerts(ctx context.Context, nextCheckAt time.Time, limit int) ([]*models.PriceAlert, error) {
+	query := `update tbl_mst_price_alert as a set next_check_at=@next_check_at
+	from (
+		select alert_id from tbl_mst_price_alert
+		where active and next_check_at <= now()
+		order by next_check_at
+		limit @limit
+		for update skip locked
+	) d
+	where a.alert_id = d.alert_id
+	returning ` + alertColumns
+	args := pgx.NamedArgs{
+		"next_check_at": nextCheckAt,
+		"limit":         limit,
+	}
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("ClaimDueAlerts QUERY failed: " + err.Error())
+		return nil, err
+	}
+	return collectAlerts(rows, "ClaimDueAlerts")
+}
+
+// RecordPriceCheck stores a check in the price history and on the alert. A failed check keeps the
+// last price; a price back above the threshold clears the last notification so the next drop is sent.
+func (db *DbClient) RecordPriceCheck(ctx context.Context, check models.PriceCheck) error {
+	query := `with h as (
+		insert into tbl_mst_price_history(alert_id,departure_date,return_date,price_minor,currency,search_id,error)
+		values(@alert_id,@departure_date,@return_date,@price_minor,@currency,@search_id,@error)
+	)
+	update tbl_mst_price_alert set
+		last_price_minor = coalesce(@price_minor, last_price_minor),
+		last_checked_at = now(),
+		next_check_at = @next_check_at,
+		notified_price_minor = case when @price_minor > threshold_minor then null else notified_price_minor end
+	where alert_id=@alert_id`
+	args := pgx.NamedArgs{
+		"alert_id":       check.AlertId,
+		"departure_date": nullDate(check.DepartureDate),
+		"return_date":    nullDate(check.ReturnDate),
+		"price_minor":    nullAmount(check.Price),
+		"currency":       check.Currency,
+		"search_id":      nullString(check.SearchId),
+		"error":          nullString(check.Error),
+		"next_check_at":  check.NextCheckAt,
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("RecordPriceCheck QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+func (db *DbClient) MarkAlertNotified(ctx context.Context, alertId int64, price money.Amount) error {
+	query := `update tbl_mst_price_alert set notified_price_minor=@price_minor, notified_at=now() where alert_id=@alert_id`
+	args := pgx.NamedArgs{
+		"alert_id":    alertId,
+		"price_minor": int64(price),
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("MarkAlertNotified QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// DeactivatePriceAlert stops checking an alert, e.g. once all of its departure dates have passed
+func (db *DbClient) DeactivatePriceAlert(ctx context.Context, alertId int64) error {
+	query := `update tbl_mst_price_alert set active=false, updated_at=now() where alert_id=@alert_id`
+	args := pgx.NamedArgs{
+		"alert_id": alertId,
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("DeactivatePriceAlert QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// ListPriceHistory returns the latest limit checks of an alert, newest first
+func (db *DbClient) ListPriceHistory(ctx context.Context, alertId int64, limit int) ([]*models.PricePoint, error) {
+	query := `select
+  coalesce(to_char(departure_date, 'YYYY-MM-DD'), '') as departure_date,
+  coalesce(to_char(return_date, 'YYYY-MM-DD'), '') as return_date,
+  price_minor,
+  currency,
+  coalesce(error, '') as error,
+  checked_at
+from
+  tbl_mst_price_history
+where
+  alert_id = @alert_id
+order by
+  checked_at desc
+limit
+  @limit`
+	args := pgx.NamedArgs{
+		"alert_id": alertId,
+		"limit":    limit,
+	}
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("ListPriceHistory QUERY failed: " + err.Error())
+		return nil, err
+	}
+
+	type historyRow struct {
+		DepartureDate string
+		ReturnDate    string
+		PriceMinor    *int64
+		Currency      string
+		Error         string
+		CheckedAt     time.Time
+	}
+
+	history, err := pgx.CollectRows(rows, pgx.RowToStructByPos[historyRow])
+	if err != nil {
+		log.Println("ListPriceHistory CollectRows failed: " + err.Error())
+		return nil, err
+	}
+
+	response := make([]*models.PricePoint, 0, len(history))
+	for _, v := range history {
+		response = append(response, &models.PricePoint{
+			DepartureDate: v.DepartureDate,
+			ReturnDate:    v.ReturnDate,
+			Price:         amountPtr(v.PriceMinor),
+			Currency:      v.Currency,
+			Error:         v.Error,
+			CheckedAt:     v.CheckedAt,
+		})
+	}
+	return response, nil
+}
+
+// AcquireLease takes or renews the named lease for holder until ttl from now. It succeeds when the
+// lease is free, expired or already held by holder, so every instance can call it on each tick.
+func (db *DbClient) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	query := `insert into tbl_mst_worker_lease as l(name,holder,expires_at)
+	values(@name,@holder,now() + make_interval(secs => @ttl))
+	on conflict (name) do update set holder=excluded.holder, expires_at=excluded.expires_at
+	where l.holder=excluded.holder or l.expires_at < now()`
+	args := pgx.NamedArgs{
+		"name":   name,
+		"holder": holder,
+		"ttl":    ttl.Seconds(),
+	}
+
+	resp, err := db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("AcquireLease QUERY failed: " + err.Error())
+		return false, err
+	}
+	return resp.RowsAffected() == 1, nil
+}
+
+// ReleaseLease gives up the named lease if holder still has it
+func (db *DbClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	query := `delete from tbl_mst_worker_lease where name=@name and holder=@holder`
+	args := pgx.NamedArgs{
+		"name":   name,
+		"holder": holder,
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("ReleaseLease QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// alertArgs holds the user-editable columns of an alert
+func alertArgs(a models.PriceAlert) pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"origin":          a.Origin,
+		"destination":     a.Destination,
+		"depart_from":     nullDate(a.DepartFrom),
+		"depart_to":       nullDate(a.DepartTo),
+		"stay_days":       a.StayDays,
+		"adults":          a.Adults,
+		"trip_class":      a.TripClass,
+		"currency":        a.Currency,
+		"threshold_minor": int64(a.Threshold),
+		"channel":         a.Channel,
+		"target":          nullString(a.Target),
+		"user_ip":         nullString(a.UserIP),
+		"active":          a.Active,
+	}
+}
+
+type alertRow struct {
+	AlertId            int64
+	UserId             int64
+	Origin             string
+	Destination        string
+	DepartFrom         time.Time
+	DepartTo           time.Time
+	StayDays           *int
+	Adults             int
+	TripClass          string
+	Currency           string
+	ThresholdMinor     int64
+	Channel            string
+	Target             string
+	UserIP             string
+	Active             bool
+	LastPriceMinor     *int64
+	LastCheckedAt      *time.Time
+	NextCheckAt        time.Time
+	NotifiedPriceMinor *int64
+	NotifiedAt         *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func collectAlerts(rows pgx.Rows, caller string) ([]*models.PriceAlert, error) {
+	alerts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[alertRow])
+	if err != nil {
+		log.Println(caller + " CollectRows failed: " + err.Error())
+		return nil, err
+	}
+
+	response := make([]*models.PriceAlert, 0, len(alerts))
+	for _, v := range alerts {
+		response = append(response, &models.PriceAlert{
+			AlertId:       v.AlertId,
+			UserId:        v.UserId,
+			Origin:        v.Origin,
+			Destination:   v.Destination,
+			DepartFrom:    v.DepartFrom.Format(time.DateOnly),
+			DepartTo:      v.DepartTo.Format(time.DateOnly),
+			StayDays:      v.StayDays,
+			Adults:        v.Adults,
+			TripClass:     v.TripClass,
+			Currency:      v.Currency,
+			Threshold:     money.Amount(v.ThresholdMinor),
+			Channel:       v.Channel,
+			Target:        v.Target,
+			UserIP:        v.UserIP,
+			Active:        v.Active,
+			LastPrice:     amountPtr(v.LastPriceMinor),
+			LastCheckedAt: v.LastCheckedAt,
+			NextCheckAt:   v.NextCheckAt,
+			NotifiedPrice: amountPtr(v.NotifiedPriceMinor),
+			NotifiedAt:    v.NotifiedAt,
+			CreatedAt:     v.CreatedAt,
+			UpdatedAt:     v.UpdatedAt,
+		})
+	}
+	return response, nil
+}
+
+// collectOneAlert returns pgx.ErrNoRows when the query matched nothing
+func collectOneAlert(rows pgx.Rows, caller string) (*models.PriceAlert, error) {
+	alerts, err := collectAlerts(rows, caller)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return alerts[0], nil
+}
+
+func amountPtr(minor *int64) *money.Amount {
+	if minor == nil {
+		return nil
+	}
+	a := money.Amount(*minor)
+	return &a
+}
@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type DBRepository interface {
 	UserRepository
+	TrackingRepository
+	AlertRepository
 }
 
 type DbClient struct {
@@ -27,6 +32,18 @@ func NewUserRepository(conn *pgxpool.Pool) UserRepository {
 	}
 }
 
+func NewTrackingRepository(conn *pgxpool.Pool) TrackingRepository {
+	return &DbClient{
+		Conn: conn,
+	}
+}
+
+func NewAlertRepository(conn *pgxpool.Pool) AlertRepository {
+	return &DbClient{
+		Conn: conn,
+	}
+}
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, req models.CreateUser) (*models.CreateUserResponse, error)
 	ListUser(ctx context.Context, req models.ListUserRequest) (*models.ListUserResponse, error)
@@ -36,3 +53,27 @@ type UserRepository interface {
 	ValidateAccess(ctx context.Context, roleId int32, resAccessId int64) bool
 	GetRoleAccessMapping(ctx context.Context) (*models.RoleAccessMapping, error)
 }
+
+type TrackingRepository interface {
+	CreateSearchSession(ctx context.Context, session models.SearchSession) error
+	CreateClickOut(ctx context.Context, click models.ClickOut) error
+	GetClickReport(ctx context.Context, req models.ClickReportRequest) ([]*models.ClickReportRow, error)
+}
+
+type AlertRepository interface {
+	CreatePriceAlert(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error)
+	UpdatePriceAlert(ctx context.Context, alert models.PriceAlert) (*models.PriceAlert, error)
+	GetPriceAlert(ctx context.Context, userId, alertId int64) (*models.PriceAlert, error)
+	ListPriceAlerts(ctx context.Context, userId int64) ([]*models.PriceAlert, error)
+	CountPriceAlerts(ctx context.Context, userId int64) (int, error)
+	DeletePriceAlert(ctx context.Context, userId, alertId int64) (bool, error)
+	ListPriceHistory(ctx context.Context, alertId int64, limit int) ([]*models.PricePoint, error)
+
+	ClaimDueAlerts(ctx context.Context, nextCheckAt time.Time, limit int) ([]*models.PriceAlert, error)
+	RecordPriceCheck(ctx context.Context, check models.PriceCheck) error
+	MarkAlertNotified(ctx context.Context, alertId int64, price money.Amount) error
+	DeactivatePriceAlert(ctx context.Context, alertId int64) error
+
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
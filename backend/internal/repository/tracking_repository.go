@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (db *DbClient) CreateSearchSession(ctx context.Context, s models.SearchSession) error {
+	query := `insert into tbl_mst_search_session(result_id,search_id,origin,destination,departure_date,return_date,
+		segment_count,trip_class,adults,children,infants,itinerary_count,min_price_minor,currency,user_id,user_ip)
+	values(@result_id,@search_id,@origin,@destination,@departure_date,@return_date,
+		@segment_count,@trip_class,@adults,@children,@infants,@itinerary_count,@min_price_minor,@currency,@user_id,@user_ip)
+	on conflict (result_id) do nothing`
+	args := pgx.NamedArgs{
+		"result_id":       s.ResultId,
+		"search_id":       nullString(s.SearchId),
+		"origin":          s.Origin,
+		"destination":     s.Destination,
+		"departure_date":  nullDate(s.DepartureDate),
+		"return_date":     nullDate(s.ReturnDate),
+		"segment_count":   s.Segments,
+		"trip_class":      nullString(s.TripClass),
+		"adults":          s.Adults,
+		"children":        s.Children,
+		"infants":         s.Infants,
+		"itinerary_count": s.Itineraries,
+		"min_price_minor": nullAmount(s.MinPrice),
+		"currency":        nullString(s.Currency),
+		"user_id":         nullID(s.UserId),
+		"user_ip":         nullString(s.UserIP),
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("CreateSearchSession QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// CreateClickOut stores a click; a click without a route takes it from the latest search session
+// of the same upstream search
+func (db *DbClient) CreateClickOut(ctx context.Context, c models.ClickOut) error {
+	query := `insert into tbl_mst_click_out(search_id,result_id,term,gate,agency_click_id,origin,destination,
+		price_minor,currency,user_id,user_ip,user_agent,referer)
+	values(@search_id,@result_id,@term,@gate,@agency_click_id,
+		coalesce(@origin,(select origin from tbl_mst_search_session where search_id=@search_id order by created_at desc limit 1)),
+		coalesce(@destination,(select destination from tbl_mst_search_session where search_id=@search_id order by created_at desc limit 1)),
+		@price_minor,@currency,@user_id,@user_ip,@user_agent,@referer)`
+	args := pgx.NamedArgs{
+		"search_id":       c.SearchId,
+		"result_id":       nullString(c.ResultId),
+		"term":            c.Term,
+		"gate":            c.Gate,
+		"agency_click_id": nullString(c.AgencyClickId),
+		"origin":          nullString(c.Origin),
+		"destination":     nullString(c.Destination),
+		"price_minor":     nullAmount(c.Price),
+		"currency":        nullString(c.Currency),
+		"user_id":         nullID(c.UserId),
+		"user_ip":         nullString(c.UserIP),
+		"user_agent":      nullString(c.UserAgent),
+		"referer":         nullString(c.Referer),
+	}
+
+	if _, err := db.Conn.Exec(ctx, query, args); err != nil {
+		log.Println("CreateClickOut QUERY failed: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// GetClickReport aggregates clicks by day, route, gate and currency between from and to, both inclusive
+func (db *DbClient) GetClickReport(ctx context.Context, req models.ClickReportRequest) ([]*models.ClickReportRow, error) {
+	args, query := buildClickReportQuery(req)
+
+	rows, err := db.Conn.Query(ctx, query, args)
+	if err != nil {
+		log.Println("GetClickReport QUERY failed: " + err.Error())
+		return nil, err
+	}
+
+	type reportRow struct {
+		Day         string
+		Origin      string
+		Destination string
+		Gate        string
+		Currency    string
+		Clicks      int64
+		Users       int64
+		Searches    int64
+		ValueMinor  int64
+	}
+
+	report, err := pgx.CollectRows(rows, pgx.RowToStructByPos[reportRow])
+	if err != nil {
+		log.Println("GetClickReport CollectRows failed: " + err.Error())
+		return nil, err
+	}
+
+	response := make([]*models.ClickReportRow, 0, len(report))
+	for _, v := range report {
+		response = append(response, &models.ClickReportRow{
+			Day:         v.Day,
+			Origin:      v.Origin,
+			Destination: v.Destination,
+			Gate:        v.Gate,
+			Currency:    v.Currency,
+			Clicks:      v.Clicks,
+			Users:       v.Users,
+			Searches:    v.Searches,
+			Value:       money.Amount(v.ValueMinor),
+		})
+	}
+	return response, nil
+}
+
+func buildClickReportQuery(req models.ClickReportRequest) (pgx.NamedArgs, string) {
+	var q strings.Builder
+	// the request dates are inclusive; compare against the start of the day after To
+	from, _ := time.Parse(time.DateOnly, req.From)
+	to, _ := time.Parse(time.DateOnly, req.To)
+	args := pgx.NamedArgs{
+		"from_date": from,
+		"to_date":   to.AddDate(0, 0, 1),
+	}
+
+	q.WriteString(`select 
+  to_char(c.created_at, 'YYYY-MM-DD') as day, 
+  coalesce(c.origin, '') as origin, 
+  coalesce(c.destination, '') as destination, 
+  c.gate, 
+  coalesce(c.currency, '') as currency, 
+  count(*) as clicks, 
+  count(distinct coalesce(c.user_id :: text, c.user_ip)) as users, 
+  count(distinct c.search_id) as searches, 
+  coalesce(sum(c.price_minor), 0):: int8 as value_minor 
+from 
+  tbl_mst_click_out c 
+where 
+  c.created_at >= @from_date 
+  and c.created_at < @to_date
+	`)
+
+	if req.Origin != "" {
+		q.WriteString(` and c.origin = @origin`)
+		args["origin"] = strings.ToUpper(req.Origin)
+	}
+	if req.Destination != "" {
+		q.WriteString(` and c.destination = @destination`)
+		args["destination"] = strings.ToUpper(req.Destination)
+	}
+	if req.Gate != "" {
+		q.WriteString(` and c.gate = @gate`)
+		args["gate"] = req.Gate
+	}
+
+	q.WriteString(`
+group by 
+  1, 2, 3, 4, 5 
+order by 
+  day desc, clicks desc`)
+
+	return args, q.String()
+}
+
+// nullString maps an empty string to SQL null
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullDate parses a yyyy-mm-dd date, mapping an empty or invalid one to SQL null
+func nullDate(s string) *time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func nullID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func nullAmount(a money.Amount) *int64 {
+	if a == 0 {
+		return nil
+	}
+	v := int64(a)
+	return &v
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/utils/cache"
+)
+
+const DefaultSearchCacheTTL = 5 * time.Minute
+
+// CachedSearcher serves repeated searches from a result cache and coalesces concurrent
+// identical searches so they share one upstream search
+type CachedSearcher struct {
+	next  provider.Searcher
+	cache cache.SearchResultCache
+	ttl   time.Duration
+	group cache.Group
+}
+
+func NewCachedSearcher(next provider.Searcher, c cache.SearchResultCache, ttl time.Duration) *CachedSearcher {
+	if ttl <= 0 {
+		ttl = DefaultSearchCacheTTL
+	}
+	return &CachedSearcher{
+		next:  next,
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// Search returns a cached result for the normalized query when there is one. Cache errors are
+// logged and treated as a miss so a broken cache never fails a search.
+func (s *CachedSearcher) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	key := cache.SearchKey(query)
+
+	if result, ok, err := s.cache.Get(ctx, key); err != nil {
+		log.Printf("[CachedSearcher] get %s: %v", key, err)
+	} else if ok {
+		return result, nil
+	}
+
+	ch := make(chan sharedResult, 1)
+	go func() {
+		// the upstream search must outlive the caller that started it since others may be waiting on it
+		v, err, _ := s.group.Do(key, func() (interface{}, error) {
+			return s.load(context.WithoutCancel(ctx), key, query)
+		})
+		result, _ := v.(*models.FlightSearchResult)
+		ch <- sharedResult{result: result, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.result, r.err
+	}
+}
+
+type sharedResult struct {
+	result *models.FlightSearchResult
+	err    error
+}
+
+func (s *CachedSearcher) load(ctx context.Context, key string, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	result, err := s.next.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// only cache searches that returned something; an empty result is often a transient upstream issue
+	if len(result.Itineraries) > 0 {
+		if err := s.cache.Set(ctx, key, result, s.ttl); err != nil {
+			log.Printf("[CachedSearcher] set %s: %v", key, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/utils/cache"
+)
+
+// countingSearcher counts upstream searches; when release is set every search waits for it
+type countingSearcher struct {
+	calls   atomic.Int32
+	release chan struct{}
+	result  *models.FlightSearchResult
+	err     error
+}
+
+func (s *countingSearcher) Search(ctx context.Context, query models.FlightQuery) (*models.FlightSearchResult, error) {
+	s.calls.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	return s.result, s.err
+}
+
+func searchQuery(origin, destination, ip string) models.FlightQuery {
+	return models.FlightQuery{
+		Segments: []models.QuerySegment{{Origin: origin, Destination: destination, Date: "2025-10-01"}},
+		Adults:   1,
+		UserIP:   ip,
+	}
+}
+
+func oneItinerary() *models.FlightSearchResult {
+	return &models.FlightSearchResult{Itineraries: []models.Itinerary{{Id: "it"}}}
+}
+
+func TestCachedSearcherSharesIdenticalSearches(t *testing.T) {
+	ctx := context.Background()
+	next := &countingSearcher{release: make(chan struct{}), result: oneItinerary()}
+	s := NewCachedSearcher(next, cache.NewSearchResultCache(cache.NewMemoryStore()), 0)
+
+	var wg sync.WaitGroup
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := s.Search(ctx, searchQuery("DEL", "BOM", ip)); err != nil || len(result.Itineraries) != 1 {
+				t.Errorf("got %+v, %v", result, err)
+			}
+		}()
+	}
+	// let every caller join the search in flight before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if _, err := s.Search(ctx, searchQuery("del", "bom", "10.0.0.4")); err != nil {
+		t.Fatal(err)
+	}
+	if n := next.calls.Load(); n != 1 {
+		t.Fatalf("upstream searched %d times, want once", n)
+	}
+}
+
+func TestCachedSearcherDoesNotCacheEmptyOrFailedSearches(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		next *countingSearcher
+	}{
+		{name: "empty", next: &countingSearcher{result: &models.FlightSearchResult{}}},
+		{name: "failed", next: &countingSearcher{err: errors.New("upstream down")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCachedSearcher(tt.next, cache.NewSearchResultCache(cache.NewMemoryStore()), 0)
+			for i := 0; i < 2; i++ {
+				_, _ = s.Search(ctx, searchQuery("DEL", "BOM", ""))
+			}
+			if n := tt.next.calls.Load(); n != 2 {
+				t.Fatalf("upstream searched %d times, want every time", n)
+			}
+		})
+	}
+}
+
+func TestCachedSearcherOutlivesItsCaller(t *testing.T) {
+	next := &countingSearcher{release: make(chan struct{}), result: oneItinerary()}
+	store := cache.NewSearchResultCache(cache.NewMemoryStore())
+	s := NewCachedSearcher(next, store, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Search(ctx, searchQuery("DEL", "BOM", "")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	close(next.release)
+	key := cache.SearchKey(searchQuery("DEL", "BOM", ""))
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if _, ok, _ := store.Get(context.Background(), key); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the abandoned search was not cached")
+		}
+	}
+}
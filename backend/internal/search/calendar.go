@@ -0,0 +1,207 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/provider"
+	"stopover.backend/internal/utils/cache"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/money"
+)
+
+const (
+	DefaultCalendarConcurrency = 6
+	DefaultCalendarCellTTL     = 30 * time.Minute
+	MaxCalendarFlexDays        = 3
+
+	// partialCellTTL bounds how long a cell from a search that had not completed is reused, so
+	// the cheaper fares that arrive later are found soon after
+	partialCellTTL = 2 * time.Minute
+
+	calendarPollAttempts = 5
+	calendarDateLayout   = "2006-01-02"
+)
+
+// CalendarCell is the cheapest fare found for one departure/return date pair
+type CalendarCell struct {
+	Departure string        `json:"departure"`
+	Return    string        `json:"return,omitempty"`
+	MinPrice  *money.Amount `json:"min_price,omitempty"`
+	Currency  string        `json:"currency,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Calendar is a matrix of minimum prices indexed as Cells[departure][return].
+// One-way calendars have a single column and no return dates.
+type Calendar struct {
+	DepartureDates []string         `json:"departure_dates"`
+	ReturnDates    []string         `json:"return_dates,omitempty"`
+	Cells          [][]CalendarCell `json:"cells"`
+}
+
+// CalendarService runs one upstream search per date pair with bounded concurrency.
+// Successful cells are cached in the store and shared between overlapping calendars, and
+// between replicas when the store is redis, so moving the centre date by a day only searches
+// the new row and column.
+type CalendarService struct {
+	api          aviasales.FlightIntegrationAPI
+	cells        *cache.Typed[CalendarCell]
+	concurrency  int
+	pollInterval time.Duration
+	ttl          time.Duration
+}
+
+func NewCalendarService(api aviasales.FlightIntegrationAPI, store cache.Store, concurrency int, pollInterval, ttl time.Duration) *CalendarService {
+	if concurrency <= 0 {
+		concurrency = DefaultCalendarConcurrency
+	}
+	if ttl <= 0 {
+		ttl = DefaultCalendarCellTTL
+	}
+	return &CalendarService{
+		api:          api,
+		cells:        cache.NewTyped[CalendarCell](store, cache.JSONCodec),
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+		ttl:          ttl,
+	}
+}
+
+// Build searches every date pair within flex days of the requested dates. The query must
+// hold one segment for one-way or two for a round trip; their dates are the centre of the grid.
+// Cells that fail carry an error instead of failing the whole calendar.
+func (cs *CalendarService) Build(ctx context.Context, query models.FlightQuery, flex int) (*Calendar, error) {
+	if len(query.Segments) == 0 || len(query.Segments) > 2 {
+		return nil, fmt.Errorf("calendar needs one or two segments, got %d", len(query.Segments))
+	}
+	if flex < 0 || flex > MaxCalendarFlexDays {
+		return nil, fmt.Errorf("flex must be between 0 and %d days", MaxCalendarFlexDays)
+	}
+
+	departures, err := dateRange(query.Segments[0].Date, flex)
+	if err != nil {
+		return nil, err
+	}
+	cal := &Calendar{DepartureDates: departures}
+
+	returns := []string{""}
+	if len(query.Segments) == 2 {
+		if returns, err = dateRange(query.Segments[1].Date, flex); err != nil {
+			return nil, err
+		}
+		cal.ReturnDates = returns
+	}
+
+	cal.Cells = make([][]CalendarCell, len(departures))
+	sem := make(chan struct{}, cs.concurrency)
+	var wg sync.WaitGroup
+
+	for i, dep := range departures {
+		cal.Cells[i] = make([]CalendarCell, len(returns))
+		for j, ret := range returns {
+			cell := &cal.Cells[i][j]
+			cell.Departure, cell.Return = dep, ret
+
+			if ret != "" && ret < dep {
+				cell.Error = "return before departure"
+				continue
+			}
+
+			wg.Add(1)
+			go func(cell *CalendarCell) {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					cell.Error = ctx.Err().Error()
+					return
+				}
+				*cell = cs.cell(ctx, query, cell.Departure, cell.Return)
+			}(cell)
+		}
+	}
+	wg.Wait()
+
+	return cal, nil
+}
+
+// cell returns the cheapest fare for one date pair, from cache when possible
+func (cs *CalendarService) cell(ctx context.Context, query models.FlightQuery, departure, ret string) CalendarCell {
+	q := query
+	q.Segments = []models.QuerySegment{{Origin: query.Segments[0].Origin, Destination: query.Segments[0].Destination, Date: departure}}
+	if ret != "" {
+		q.Segments = append(q.Segments, models.QuerySegment{Origin: query.Segments[1].Origin, Destination: query.Segments[1].Destination, Date: ret})
+	}
+
+	key := calendarKey(q)
+	if cached, ok, err := cs.cells.Get(ctx, key); err != nil {
+		log.Printf("[Calendar] %s cache get failed: %v", key, err)
+	} else if ok {
+		return cached
+	}
+
+	cell := CalendarCell{Departure: departure, Return: ret}
+	initResp, err := cs.api.InitSearch(ctx, provider.ToAviasalesRequest(q))
+	if err != nil {
+		log.Printf("[Calendar] %s init failed: %v", key, err)
+		cell.Error = "search failed"
+		return cell
+	}
+
+	results, err := cs.api.GetSearchResultsWithPolling(ctx, initResp.SearchID, calendarPollAttempts, cs.pollInterval, aviasales.DefaultPipeline...)
+	if err != nil {
+		log.Printf("[Calendar] %s poll failed: %v", key, err)
+		cell.Error = "search failed"
+		return cell
+	}
+
+	mapped := provider.MapAviasalesResults("aviasales", results)
+	cell.Currency = mapped.Currency
+	for _, it := range mapped.Itineraries {
+		if fare, ok := it.BestFare(); ok && (cell.MinPrice == nil || fare.UnifiedPrice < *cell.MinPrice) {
+			p := fare.UnifiedPrice
+			cell.MinPrice = &p
+		}
+	}
+
+	ttl := cs.ttl
+	if !results.Complete {
+		ttl = min(ttl, partialCellTTL)
+	}
+	if err := cs.cells.Set(ctx, key, cell, ttl); err != nil {
+		log.Printf("[Calendar] %s cache set failed: %v", key, err)
+	}
+	return cell
+}
+
+// calendarKey identifies a cell search by route, dates, passengers and class; the version prefix
+// lets a change to the cached cell be rolled out without reading stale entries
+func calendarKey(q models.FlightQuery) string {
+	var b strings.Builder
+	b.WriteString("v1:")
+	for _, s := range q.Segments {
+		b.WriteString(s.Origin + "-" + s.Destination + "@" + s.Date + "/")
+	}
+	fmt.Fprintf(&b, "%d.%d.%d/%s", q.Adults, q.Children, q.Infants, q.TripClass)
+	return b.String()
+}
+
+// dateRange returns the dates from centre-flex to centre+flex
+func dateRange(centre string, flex int) ([]string, error) {
+	d, err := time.Parse(calendarDateLayout, centre)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", centre)
+	}
+	dates := make([]string, 0, 2*flex+1)
+	for i := -flex; i <= flex; i++ {
+		dates = append(dates, d.AddDate(0, 0, i).Format(calendarDateLayout))
+	}
+	return dates, nil
+}
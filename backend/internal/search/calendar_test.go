@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/utils/cache"
+	"stopover.backend/pkg/aviasales"
+)
+
+// stubAPI answers every search with an empty result that is complete or not
+type stubAPI struct {
+	complete bool
+	fail     bool
+
+	mu       sync.Mutex
+	searches int
+}
+
+func (s *stubAPI) InitSearch(ctx context.Context, req aviasales.FlightSearchRequest) (*aviasales.FlightSearchInitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches++
+	if s.fail {
+		return nil, errors.New("supplier down")
+	}
+	return &aviasales.FlightSearchInitResponse{SearchID: "s"}, nil
+}
+
+func (s *stubAPI) GetSearchResultsWithPolling(ctx context.Context, searchID string, maxAttempts int, pollInterval time.Duration, processors ...aviasales.Processor) (*aviasales.FlightSearchResponseWrapper, error) {
+	return &aviasales.FlightSearchResponseWrapper{SearchID: searchID, Currency: "usd", Complete: s.complete}, nil
+}
+
+func (s *stubAPI) GetSearchResults(ctx context.Context, searchID string, processors ...aviasales.Processor) (*aviasales.FlightSearchResponseWrapper, error) {
+	return s.GetSearchResultsWithPolling(ctx, searchID, 1, 0, processors...)
+}
+
+func (s *stubAPI) GetBookingLink(ctx context.Context, searchID, termURL string) (*aviasales.BookingLink, error) {
+	return nil, errors.New("not supported")
+}
+
+// ttlStore records the ttl of every value it stores
+type ttlStore struct {
+	cache.Store
+
+	mu   sync.Mutex
+	ttls []time.Duration
+}
+
+func (s *ttlStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	s.ttls = append(s.ttls, ttl)
+	s.mu.Unlock()
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func TestCalendarCellTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		api      *stubAPI
+		wantTTLs []time.Duration
+	}{
+		{name: "complete search is cached for the full ttl", api: &stubAPI{complete: true}, wantTTLs: []time.Duration{time.Hour}},
+		{name: "partial search is cached briefly", api: &stubAPI{}, wantTTLs: []time.Duration{partialCellTTL}},
+		{name: "failed search is not cached", api: &stubAPI{fail: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &ttlStore{Store: cache.NewMemoryStore()}
+			cs := NewCalendarService(tt.api, store, 1, time.Millisecond, time.Hour)
+			query := models.FlightQuery{
+				Segments:  []models.QuerySegment{{Origin: "DEL", Destination: "COK", Date: "2025-10-01"}},
+				Adults:    1,
+				TripClass: "Y",
+			}
+
+			for i := 0; i < 2; i++ {
+				if _, err := cs.Build(context.Background(), query, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(store.ttls) != len(tt.wantTTLs) {
+				t.Fatalf("stored %d cells with ttls %v, want %v", len(store.ttls), store.ttls, tt.wantTTLs)
+			}
+			for i, ttl := range store.ttls {
+				if ttl != tt.wantTTLs[i] {
+					t.Errorf("ttl = %s, want %s", ttl, tt.wantTTLs[i])
+				}
+			}
+			// a cached cell answers the second calendar without searching again
+			if want := 2 - len(tt.wantTTLs); tt.api.searches != want {
+				t.Errorf("searches = %d, want %d", tt.api.searches, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"sort"
+	"strconv"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+// FacetBucket counts the itineraries sharing one value and the cheapest price among them
+type FacetBucket struct {
+	Key      string       `json:"key"`
+	Count    int          `json:"count"`
+	MinPrice money.Amount `json:"min_price"`
+}
+
+// Facets summarize a result set for filter sidebars
+type Facets struct {
+	Airlines            []FacetBucket `json:"airlines"`
+	Stops               []FacetBucket `json:"stops"`
+	DepartureTimes      []FacetBucket `json:"departure_times"`
+	OriginAirports      []FacetBucket `json:"origin_airports"`
+	DestinationAirports []FacetBucket `json:"destination_airports"`
+	Connections         []FacetBucket `json:"connections"`
+}
+
+// connection warnings in the order they are listed; each matches a No flag of Filter
+var connectionOrder = []string{"short", "overnight", "airport_change", "self_transfer", "red_eye"}
+
+// departure time buckets by local hour of the outbound departure
+var departureBuckets = []struct {
+	key   string
+	until int
+}{
+	{"night", 6},
+	{"morning", 12},
+	{"afternoon", 18},
+	{"evening", 24},
+}
+
+type facetCounter struct {
+	buckets map[string]*FacetBucket
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{buckets: make(map[string]*FacetBucket)}
+}
+
+func (fc *facetCounter) add(key string, price money.Amount, priced bool) {
+	b, ok := fc.buckets[key]
+	if !ok {
+		b = &FacetBucket{Key: key}
+		fc.buckets[key] = b
+	}
+	b.Count++
+	if priced && (b.MinPrice == 0 || price < b.MinPrice) {
+		b.MinPrice = price
+	}
+}
+
+// list returns the buckets ordered by key, or by the given key order when provided
+func (fc *facetCounter) list(order []string) []FacetBucket {
+	out := make([]FacetBucket, 0, len(fc.buckets))
+	if order != nil {
+		for _, k := range order {
+			if b, ok := fc.buckets[k]; ok {
+				out = append(out, *b)
+			}
+		}
+		return out
+	}
+	for _, b := range fc.buckets {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// BuildFacets counts itineraries per airline, number of stops, outbound departure time and airport,
+// and connection warning. An itinerary is counted once per distinct value it carries, e.g. once
+// for each of its airlines.
+func BuildFacets(its []models.Itinerary) Facets {
+	airlines := newFacetCounter()
+	stops := newFacetCounter()
+	departures := newFacetCounter()
+	origins := newFacetCounter()
+	destinations := newFacetCounter()
+	connections := newFacetCounter()
+
+	maxStop := 0
+	for _, it := range its {
+		fare, priced := it.BestFare()
+		p := fare.UnifiedPrice
+
+		seen := make(map[string]struct{})
+		for _, c := range it.Carriers {
+			if _, ok := seen[c]; ok {
+				continue
+			}
+			seen[c] = struct{}{}
+			airlines.add(c, p, priced)
+		}
+
+		n := maxStops(it)
+		if n > maxStop {
+			maxStop = n
+		}
+		stops.add(strconv.Itoa(n), p, priced)
+
+		cs := summarizeConnections(it)
+		for i, flagged := range []bool{cs.short, cs.overnight, cs.airportChange, cs.selfTransfer, cs.redEye} {
+			if flagged {
+				connections.add(connectionOrder[i], p, priced)
+			}
+		}
+
+		if len(it.Legs) == 0 {
+			continue
+		}
+		outbound := it.Legs[0]
+		hour := outbound.Departure.Hour()
+		for _, b := range departureBuckets {
+			if hour < b.until {
+				departures.add(b.key, p, priced)
+				break
+			}
+		}
+		origins.add(outbound.Origin, p, priced)
+		destinations.add(outbound.Destination, p, priced)
+	}
+
+	stopOrder := make([]string, 0, maxStop+1)
+	for i := 0; i <= maxStop; i++ {
+		stopOrder = append(stopOrder, strconv.Itoa(i))
+	}
+	departureOrder := make([]string, 0, len(departureBuckets))
+	for _, b := range departureBuckets {
+		departureOrder = append(departureOrder, b.key)
+	}
+
+	return Facets{
+		Airlines:            airlines.list(nil),
+		Stops:               stops.list(stopOrder),
+		DepartureTimes:      departures.list(departureOrder),
+		OriginAirports:      origins.list(nil),
+		DestinationAirports: destinations.list(nil),
+		Connections:         connections.list(connectionOrder),
+	}
+}
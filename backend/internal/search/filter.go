@@ -0,0 +1,313 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/pkg/money"
+)
+
+type SortOrder string
+
+const (
+	SortBest     SortOrder = "best"
+	SortCheapest SortOrder = "cheapest"
+	SortFastest  SortOrder = "fastest"
+)
+
+// weights of the composite "best" score; price matters most, then duration, then stops
+const (
+	bestPriceWeight    = 0.6
+	bestDurationWeight = 0.3
+	bestStopWeight     = 0.1
+)
+
+// ParseSortOrder validates a sort query value, defaulting to best
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch SortOrder(strings.ToLower(s)) {
+	case "", SortBest:
+		return SortBest, nil
+	case SortCheapest:
+		return SortCheapest, nil
+	case SortFastest:
+		return SortFastest, nil
+	}
+	return "", fmt.Errorf("invalid sort %q: expected best, cheapest or fastest", s)
+}
+
+// Filter narrows down itineraries; zero values disable the corresponding check.
+// Time windows are minutes after local midnight at the airport and apply to every leg;
+// ArriveBefore is on the local date the leg departs, so a leg landing the next day never matches.
+// Airlines keeps itineraries flown only by the listed carriers.
+// Origins and Destinations are the airports the outbound leg may use, which narrows a
+// city search down to some of the city's airports. Layover bounds are minutes and apply to
+// every connection; the No flags drop itineraries with any connection or flight so flagged.
+type Filter struct {
+	MaxStops        *int
+	Airlines        []string
+	Origins         []string
+	Destinations    []string
+	DepartAfter     *int
+	ArriveBefore    *int
+	MaxDuration     int
+	MinPrice        money.Amount
+	MaxPrice        money.Amount
+	MinLayover      int
+	MaxLayover      int
+	NoShort         bool
+	NoOvernight     bool
+	NoAirportChange bool
+	NoSelfTransfer  bool
+	NoRedEye        bool
+}
+
+// ParseClock reads an "HH:MM" time of day as minutes after midnight
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Apply returns the itineraries that pass every active check
+func (f Filter) Apply(its []models.Itinerary) []models.Itinerary {
+	allowed := codeSet(f.Airlines)
+	origins, destinations := codeSet(f.Origins), codeSet(f.Destinations)
+
+	out := make([]models.Itinerary, 0, len(its))
+	for _, it := range its {
+		if f.matches(it, allowed) && outboundMatches(it, origins, destinations) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func codeSet(codes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		set[strings.ToUpper(c)] = struct{}{}
+	}
+	return set
+}
+
+func outboundMatches(it models.Itinerary, origins, destinations map[string]struct{}) bool {
+	if len(origins) == 0 && len(destinations) == 0 {
+		return true
+	}
+	if len(it.Legs) == 0 {
+		return false
+	}
+	if _, ok := origins[it.Legs[0].Origin]; len(origins) > 0 && !ok {
+		return false
+	}
+	if _, ok := destinations[it.Legs[0].Destination]; len(destinations) > 0 && !ok {
+		return false
+	}
+	return true
+}
+
+func (f Filter) matches(it models.Itinerary, allowed map[string]struct{}) bool {
+	if f.MaxStops != nil && maxStops(it) > *f.MaxStops {
+		return false
+	}
+
+	if len(allowed) > 0 {
+		for _, c := range it.Carriers {
+			if _, ok := allowed[strings.ToUpper(c)]; !ok {
+				return false
+			}
+		}
+	}
+
+	if f.MaxDuration > 0 && it.DurationMinutes > f.MaxDuration {
+		return false
+	}
+
+	for _, leg := range it.Legs {
+		if f.DepartAfter != nil && minuteOfDay(leg.Departure) < *f.DepartAfter {
+			return false
+		}
+		if f.ArriveBefore != nil && !arrivesBefore(leg, *f.ArriveBefore) {
+			return false
+		}
+	}
+
+	if !f.connectionsMatch(summarizeConnections(it)) {
+		return false
+	}
+
+	if f.MinPrice > 0 || f.MaxPrice > 0 {
+		fare, ok := it.BestFare()
+		if !ok {
+			return false
+		}
+		if f.MinPrice > 0 && fare.UnifiedPrice < f.MinPrice {
+			return false
+		}
+		if f.MaxPrice > 0 && fare.UnifiedPrice > f.MaxPrice {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) connectionsMatch(cs connectionSummary) bool {
+	if cs.layovers > 0 {
+		if f.MinLayover > 0 && cs.minLayover < f.MinLayover {
+			return false
+		}
+		if f.MaxLayover > 0 && cs.maxLayover > f.MaxLayover {
+			return false
+		}
+	}
+	return !(f.NoShort && cs.short ||
+		f.NoOvernight && cs.overnight ||
+		f.NoAirportChange && cs.airportChange ||
+		f.NoSelfTransfer && cs.selfTransfer ||
+		f.NoRedEye && cs.redEye)
+}
+
+// connectionSummary folds the layovers and red-eye flags of every leg of an itinerary; layovers
+// counts those of known duration, which alone bound minLayover and maxLayover
+type connectionSummary struct {
+	layovers                    int
+	minLayover, maxLayover      int
+	short, overnight            bool
+	airportChange, selfTransfer bool
+	redEye                      bool
+}
+
+func summarizeConnections(it models.Itinerary) connectionSummary {
+	var cs connectionSummary
+	for _, leg := range it.Legs {
+		for _, s := range leg.Segments {
+			cs.redEye = cs.redEye || s.RedEye
+		}
+		for _, l := range leg.Layovers {
+			cs.short = cs.short || l.Short
+			cs.overnight = cs.overnight || l.Overnight
+			cs.airportChange = cs.airportChange || l.AirportChange
+			cs.selfTransfer = cs.selfTransfer || l.SelfTransfer
+
+			// a layover of unknown time says nothing about how long the connections are
+			if l.DurationMinutes == 0 {
+				continue
+			}
+			if cs.layovers == 0 || l.DurationMinutes < cs.minLayover {
+				cs.minLayover = l.DurationMinutes
+			}
+			if l.DurationMinutes > cs.maxLayover {
+				cs.maxLayover = l.DurationMinutes
+			}
+			cs.layovers++
+		}
+	}
+	return cs
+}
+
+// Sort orders itineraries in place
+func Sort(its []models.Itinerary, order SortOrder) {
+	switch order {
+	case SortCheapest:
+		sort.SliceStable(its, func(i, j int) bool {
+			return price(its[i]) < price(its[j])
+		})
+	case SortFastest:
+		sort.SliceStable(its, func(i, j int) bool {
+			if its[i].DurationMinutes == its[j].DurationMinutes {
+				return price(its[i]) < price(its[j])
+			}
+			return its[i].DurationMinutes < its[j].DurationMinutes
+		})
+	default:
+		scores := bestScores(its)
+		idx := make([]int, len(its))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(a, b int) bool {
+			return scores[idx[a]] < scores[idx[b]]
+		})
+		sorted := make([]models.Itinerary, len(its))
+		for i, k := range idx {
+			sorted[i] = its[k]
+		}
+		copy(its, sorted)
+	}
+}
+
+// bestScores rates each itinerary relative to the cheapest and fastest in the set; lower is better
+func bestScores(its []models.Itinerary) []float64 {
+	minPrice, minDuration := 0.0, 0.0
+	for _, it := range its {
+		p := price(it).Float64()
+		if p > 0 && (minPrice == 0 || p < minPrice) {
+			minPrice = p
+		}
+		d := float64(it.DurationMinutes)
+		if d > 0 && (minDuration == 0 || d < minDuration) {
+			minDuration = d
+		}
+	}
+
+	scores := make([]float64, len(its))
+	for i, it := range its {
+		score := 0.0
+		if minPrice > 0 {
+			score += bestPriceWeight * price(it).Float64() / minPrice
+		}
+		if minDuration > 0 {
+			score += bestDurationWeight * float64(it.DurationMinutes) / minDuration
+		}
+		score += bestStopWeight * float64(maxStops(it))
+		scores[i] = score
+	}
+	return scores
+}
+
+// price is the cheapest fare of an itinerary; itineraries without fares sort last
+func price(it models.Itinerary) money.Amount {
+	fare, ok := it.BestFare()
+	if !ok {
+		return money.Amount(1<<62 - 1)
+	}
+	return fare.UnifiedPrice
+}
+
+func maxStops(it models.Itinerary) int {
+	stops := 0
+	for _, leg := range it.Legs {
+		if leg.Stops > stops {
+			stops = leg.Stops
+		}
+	}
+	return stops
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// arrivesBefore reports whether a leg lands by the minute of day on the local date it departs.
+// Legs landing on an earlier date, after crossing the date line westwards, always do.
+func arrivesBefore(leg models.Leg, minute int) bool {
+	switch days := localDays(leg.Departure, leg.Arrival); {
+	case days < 0:
+		return true
+	case days > 0:
+		return false
+	}
+	return minuteOfDay(leg.Arrival) <= minute
+}
+
+// localDays is the number of calendar days from the local date of a to the local date of b
+func localDays(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
@@ -0,0 +1,128 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"stopover.backend/internal/models"
+)
+
+func TestFilterArriveBefore(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	pst := time.FixedZone("PST", -8*3600)
+	at := func(loc *time.Location, day, hour, minute int) time.Time {
+		return time.Date(2025, 10, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name           string
+		depart, arrive time.Time
+		arriveBy       string
+		want           bool
+	}{
+		{name: "same day before", depart: at(ist, 1, 6, 0), arrive: at(ist, 1, 9, 10), arriveBy: "12:00", want: true},
+		{name: "same day at the bound", depart: at(ist, 1, 6, 0), arrive: at(ist, 1, 12, 0), arriveBy: "12:00", want: true},
+		{name: "same day after", depart: at(ist, 1, 6, 0), arrive: at(ist, 1, 14, 30), arriveBy: "12:00", want: false},
+		{name: "overnight with an early clock time", depart: at(ist, 1, 22, 0), arrive: at(ist, 2, 1, 15), arriveBy: "12:00", want: false},
+		{name: "lands the previous local day", depart: at(ist, 2, 1, 0), arrive: at(pst, 1, 20, 0), arriveBy: "12:00", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound, err := ParseClock(tt.arriveBy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			it := models.Itinerary{Legs: []models.Leg{{Departure: tt.depart, Arrival: tt.arrive}}}
+			got := len(Filter{ArriveBefore: &bound}.Apply([]models.Itinerary{it})) == 1
+			if got != tt.want {
+				t.Errorf("matched = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterAirlinesRequiresEveryCarrier(t *testing.T) {
+	its := []models.Itinerary{
+		{Id: "ai", Carriers: []string{"AI"}},
+		{Id: "ai+6e", Carriers: []string{"AI", "6E"}},
+		{Id: "uk", Carriers: []string{"UK"}},
+	}
+	got := Filter{Airlines: []string{"ai", "uk"}}.Apply(its)
+	if len(got) != 2 || got[0].Id != "ai" || got[1].Id != "uk" {
+		t.Fatalf("got %+v, want the itineraries flown only by AI or UK", got)
+	}
+}
+
+func TestSummarizeConnections(t *testing.T) {
+	legs := func(layovers ...[]models.Layover) models.Itinerary {
+		var it models.Itinerary
+		for _, ls := range layovers {
+			it.Legs = append(it.Legs, models.Leg{Layovers: ls})
+		}
+		return it
+	}
+
+	tests := []struct {
+		name string
+		it   models.Itinerary
+		want connectionSummary
+	}{
+		{name: "direct", it: legs(nil), want: connectionSummary{}},
+		{
+			name: "bounds across legs",
+			it:   legs([]models.Layover{{DurationMinutes: 90}}, []models.Layover{{DurationMinutes: 45, Short: true}, {DurationMinutes: 600, Overnight: true}}),
+			want: connectionSummary{layovers: 3, minLayover: 45, maxLayover: 600, short: true, overnight: true},
+		},
+		{
+			name: "unknown time keeps its flags but not the bounds",
+			it:   legs([]models.Layover{{AirportChange: true, SelfTransfer: true}, {DurationMinutes: 120}}),
+			want: connectionSummary{layovers: 1, minLayover: 120, maxLayover: 120, airportChange: true, selfTransfer: true},
+		},
+		{
+			name: "only unknown times",
+			it:   legs([]models.Layover{{}}),
+			want: connectionSummary{},
+		},
+		{
+			name: "red-eye segment",
+			it:   models.Itinerary{Legs: []models.Leg{{Segments: []models.FlightSegment{{}, {RedEye: true}}}}},
+			want: connectionSummary{redEye: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeConnections(tt.it); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionsMatch(t *testing.T) {
+	twoStops := connectionSummary{layovers: 2, minLayover: 50, maxLayover: 300, short: true, selfTransfer: true}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		cs     connectionSummary
+		want   bool
+	}{
+		{name: "no filter", cs: twoStops, want: true},
+		{name: "min layover met", filter: Filter{MinLayover: 50}, cs: twoStops, want: true},
+		{name: "min layover missed", filter: Filter{MinLayover: 60}, cs: twoStops, want: false},
+		{name: "max layover met", filter: Filter{MaxLayover: 300}, cs: twoStops, want: true},
+		{name: "max layover exceeded", filter: Filter{MaxLayover: 240}, cs: twoStops, want: false},
+		{name: "bounds ignore direct flights", filter: Filter{MinLayover: 60, MaxLayover: 120}, cs: connectionSummary{}, want: true},
+		{name: "no short", filter: Filter{NoShort: true}, cs: twoStops, want: false},
+		{name: "no self transfer", filter: Filter{NoSelfTransfer: true}, cs: twoStops, want: false},
+		{name: "no overnight passes", filter: Filter{NoOvernight: true, NoAirportChange: true, NoRedEye: true}, cs: twoStops, want: true},
+		{name: "no red-eye", filter: Filter{NoRedEye: true}, cs: connectionSummary{redEye: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.connectionsMatch(tt.cs); got != tt.want {
+				t.Errorf("matched = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package search
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// PageInfo describes the slice of results returned in one response
+type PageInfo struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// Paginate returns the requested 1-based page of items. Out of range pages are empty.
+func Paginate[T any](items []T, page, pageSize int) ([]T, PageInfo) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	info := PageInfo{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      len(items),
+		TotalPages: (len(items) + pageSize - 1) / pageSize,
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(items) {
+		return make([]T, 0), info
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end], info
+}
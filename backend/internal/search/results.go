@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/utils/cache"
+)
+
+const DefaultResultTTL = 10 * time.Minute
+
+// ResultCache keeps completed search results in a store so later pages and filter changes are
+// served without searching again, by whichever replica the request lands on when the store is
+// redis. Cache errors are logged; a result that could not be stored or read is treated as expired.
+type ResultCache struct {
+	results cache.SearchResultCache
+	ttl     time.Duration
+}
+
+func NewResultCache(store cache.Store, ttl time.Duration) *ResultCache {
+	if ttl <= 0 {
+		ttl = DefaultResultTTL
+	}
+	return &ResultCache{
+		results: cache.NewSearchResultCache(store),
+		ttl:     ttl,
+	}
+}
+
+// Put stores a result and returns the id it can be fetched with
+func (rc *ResultCache) Put(ctx context.Context, result *models.FlightSearchResult) string {
+	id := newSessionID()
+	if err := rc.results.Set(ctx, id, result, rc.ttl); err != nil {
+		log.Printf("[ResultCache] set %s: %v", id, err)
+	}
+	return id
+}
+
+// Get returns a result that has not yet expired
+func (rc *ResultCache) Get(ctx context.Context, id string) (*models.FlightSearchResult, bool) {
+	result, ok, err := rc.results.Get(ctx, id)
+	if err != nil {
+		log.Printf("[ResultCache] get %s: %v", id, err)
+		return nil, false
+	}
+	return result, ok
+}
+
+// ResultPage is one page of a filtered and sorted result set plus facets of the whole set
+type ResultPage struct {
+	ResultID    string                   `json:"result_id"`
+	Currency    string                   `json:"currency,omitempty"`
+	Itineraries []models.Itinerary       `json:"itineraries"`
+	Providers   []models.ProviderOutcome `json:"providers,omitempty"`
+	Pagination  PageInfo                 `json:"pagination"`
+	Facets      Facets                   `json:"facets"`
+}
+
+// NewResultPage filters, sorts and paginates a result. Facets describe the unfiltered set so
+// every option stays visible in the sidebar after a filter is applied.
+func NewResultPage(id string, result *models.FlightSearchResult, filter Filter, order SortOrder, page, pageSize int) ResultPage {
+	its := filter.Apply(result.Itineraries)
+	Sort(its, order)
+	items, info := Paginate(its, page, pageSize)
+
+	return ResultPage{
+		ResultID:    id,
+		Currency:    result.Currency,
+		Itineraries: items,
+		Providers:   result.Providers,
+		Pagination:  info,
+		Facets:      BuildFacets(result.Itineraries),
+	}
+}
@@ -0,0 +1,245 @@
+package search
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"stopover.backend/internal/models"
+	"stopover.backend/internal/provider"
+	"stopover.backend/pkg/aviasales"
+)
+
+const (
+	DefaultSessionTTL   = 15 * time.Minute
+	DefaultPollAttempts = 10
+	DefaultPollInterval = 2 * time.Second
+)
+
+// sessions poll Aviasales directly, so their fares are attributed to that provider
+const providerName = "aviasales"
+
+// Session tracks one upstream search and the proposals accumulated for it so far
+type Session struct {
+	ID        string
+	SearchID  string
+	Request   aviasales.FlightSearchRequest
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	mu       sync.RWMutex
+	results  *aviasales.ResultAccumulator
+	complete bool
+	err      error
+	updated  chan struct{}
+}
+
+// Snapshot is a point-in-time copy of a session returned to API callers
+type Snapshot struct {
+	ID          string             `json:"id"`
+	SearchID    string             `json:"search_id"`
+	Complete    bool               `json:"complete"`
+	Error       string             `json:"error,omitempty"`
+	Itineraries []models.Itinerary `json:"itineraries"`
+	Currency    string             `json:"currency,omitempty"`
+	Pagination  *PageInfo          `json:"pagination,omitempty"`
+	Facets      *Facets            `json:"facets,omitempty"`
+	CreatedAt   int64              `json:"created_at"`
+	ExpiresAt   int64              `json:"expires_at"`
+}
+
+// Snapshot copies the current state of the session
+func (s *Session) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := provider.MapAviasalesResults(providerName, s.results.Result())
+	snap := Snapshot{
+		ID:          s.ID,
+		SearchID:    s.SearchID,
+		Complete:    s.complete,
+		Itineraries: res.Itineraries,
+		Currency:    res.Currency,
+		CreatedAt:   s.CreatedAt.Unix(),
+		ExpiresAt:   s.ExpiresAt.Unix(),
+	}
+	if s.err != nil {
+		snap.Error = s.err.Error()
+	}
+	return snap
+}
+
+// Batch holds the itineraries added to or updated in a session after a given version.
+// Version is the one to ask for next.
+type Batch struct {
+	Itineraries []models.Itinerary `json:"itineraries"`
+	Currency    string             `json:"currency,omitempty"`
+	Total       int                `json:"total"`
+	Version     int                `json:"-"`
+}
+
+// Since returns the itineraries added or given more fares after version, whether the session is
+// complete, and a channel that is closed on the next update. An updated itinerary is returned
+// again in full under the same id.
+func (s *Session) Since(version int) (Batch, bool, <-chan struct{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wrapper, current := s.results.Since(version)
+	res := provider.MapAviasalesResults(providerName, wrapper)
+	batch := Batch{
+		Itineraries: res.Itineraries,
+		Currency:    res.Currency,
+		Total:       s.results.Len(),
+		Version:     current,
+	}
+	return batch, s.complete, s.updated
+}
+
+// Err returns the error the session finished with, if any
+func (s *Session) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// notify wakes every waiter on the current update channel; callers must hold s.mu
+func (s *Session) notify() {
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// merge adds a batch of results to the session and wakes any waiters
+func (s *Session) merge(batch *aviasales.FlightSearchResponseWrapper) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := s.results.Version()
+	added := s.results.Add(batch)
+	// known proposals that gained fares are news to waiters as well
+	if s.results.Version() != before {
+		s.notify()
+	}
+	return added
+}
+
+func (s *Session) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.complete = true
+	s.err = err
+	s.notify()
+}
+
+// Poll keeps fetching upstream results until the search completes, the attempts are exhausted or ctx is done
+func (s *Session) Poll(ctx context.Context, api aviasales.FlightIntegrationAPI, maxAttempts int, pollInterval time.Duration) {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result, err := api.GetSearchResults(ctx, s.SearchID, aviasales.DefaultPipeline...)
+		if err != nil {
+			log.Printf("[Session %s] Poll attempt %d failed: %v", s.ID, attempt, err)
+			lastErr = err
+		} else {
+			lastErr = nil
+			added := s.merge(result)
+			log.Printf("[Session %s] Poll attempt %d added %d proposals", s.ID, attempt, added)
+			if result.Complete {
+				break
+			}
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			s.finish(ctx.Err())
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+	s.finish(lastErr)
+}
+
+// Store keeps search sessions in memory until they expire
+type Store struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	ttl      time.Duration
+}
+
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Store{
+		sessions: make(map[string]*Session),
+		ttl:      ttl,
+	}
+}
+
+// Create registers a new session for an upstream search ID
+func (st *Store) Create(searchID string, req aviasales.FlightSearchRequest) *Session {
+	now := time.Now()
+	s := &Session{
+		ID:        newSessionID(),
+		SearchID:  searchID,
+		Request:   req,
+		CreatedAt: now,
+		ExpiresAt: now.Add(st.ttl),
+		results:   aviasales.NewResultAccumulator(searchID),
+		updated:   make(chan struct{}),
+	}
+
+	st.mu.Lock()
+	st.sessions[s.ID] = s
+	st.mu.Unlock()
+	return s
+}
+
+// Get returns a session that has not yet expired
+func (st *Store) Get(id string) (*Session, bool) {
+	st.mu.RLock()
+	s, ok := st.sessions[id]
+	st.mu.RUnlock()
+	if !ok || time.Now().After(s.ExpiresAt) {
+		return nil, false
+	}
+	return s, true
+}
+
+// StartJanitor removes expired sessions every interval until ctx is cancelled
+func (st *Store) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				st.evictExpired()
+			}
+		}
+	}()
+}
+
+func (st *Store) evictExpired() {
+	now := time.Now()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id, s := range st.sessions {
+		if now.After(s.ExpiresAt) {
+			delete(st.sessions, id)
+		}
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"testing"
+
+	"stopover.backend/pkg/aviasales"
+)
+
+func flightProposal(sign, number string, gates ...string) aviasales.Proposal {
+	p := aviasales.Proposal{
+		Sign: sign,
+		Segment: []aviasales.FlightSegment{{Flight: []aviasales.Flight{{
+			Departure: "DEL", Arrival: "BOM", DepartureDate: "2025-10-01", DepartureTime: "06:00",
+			ArrivalDate: "2025-10-01", ArrivalTime: "08:10", MarketingCarrier: "AI", Number: number,
+		}}}},
+		Terms: make(map[string]aviasales.TermData),
+	}
+	for _, g := range gates {
+		p.Terms[g] = aviasales.TermData{Currency: "inr", Price: 5000, UnifiedPrice: 5000}
+	}
+	return p
+}
+
+func TestSessionSinceResendsItinerariesThatGainedFares(t *testing.T) {
+	s := NewStore(0).Create("search", aviasales.FlightSearchRequest{})
+
+	s.merge(&aviasales.FlightSearchResponseWrapper{Proposals: []aviasales.Proposal{flightProposal("a", "101", "g1"), flightProposal("b", "202", "g1")}})
+	first, _, updated := s.Since(0)
+	if len(first.Itineraries) != 2 || first.Total != 2 {
+		t.Fatalf("first batch %+v", first)
+	}
+
+	s.merge(&aviasales.FlightSearchResponseWrapper{Proposals: []aviasales.Proposal{flightProposal("b", "202", "g2")}})
+	select {
+	case <-updated:
+	default:
+		t.Fatal("waiters were not woken by the merged fares")
+	}
+
+	second, _, updated := s.Since(first.Version)
+	if len(second.Itineraries) != 1 || second.Itineraries[0].Id != first.Itineraries[1].Id || len(second.Itineraries[0].Fares) != 2 || second.Total != 2 {
+		t.Fatalf("second batch %+v, want itinerary %s again with both fares", second, first.Itineraries[1].Id)
+	}
+
+	s.merge(&aviasales.FlightSearchResponseWrapper{Proposals: []aviasales.Proposal{flightProposal("b", "202", "g1")}})
+	select {
+	case <-updated:
+		t.Fatal("waiters were woken without a change")
+	default:
+	}
+	if third, _, _ := s.Since(second.Version); len(third.Itineraries) != 0 {
+		t.Fatalf("third batch %+v, want nothing new", third)
+	}
+}
@@ -14,7 +14,6 @@ type CacheService interface {
 	DeleteValue(ctx context.Context, field string) error
 	KeyExists(ctx context.Context, field string) (bool, error)
 	GetAllValues(ctx context.Context) (map[string]string, error)
-	FlushAll(ctx context.Context) error
 }
 
 type redisClient struct {
@@ -71,8 +70,3 @@ func (r *redisClient) KeyExists(ctx context.Context, field string) (bool, error)
 func (r *redisClient) GetAllValues(ctx context.Context) (map[string]string, error) {
 	return r.rdb.HGetAll(ctx, r.cfg.RedisRoleAccessKey).Result()
 }
-
-// FlushAll clears the entire Redis DB (use cautiously)
-func (r *redisClient) FlushAll(ctx context.Context) error {
-	return r.rdb.FlushAll(ctx).Err()
-}
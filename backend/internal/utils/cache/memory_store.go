@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// memoryStore is the in-process store used in development and when redis is not configured.
+// Values are copied in and out so callers never share memory with the cache, as with redis.
+type memoryStore struct {
+	mu        sync.Mutex
+	items     map[string]memoryItem
+	lastSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		items:     make(map[string]memoryItem),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if item.expired(time.Now()) {
+		delete(m.items, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), item.value...), true, nil
+}
+
+func (m *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range keys {
+		delete(m.items, k)
+	}
+	return nil
+}
+
+func (m *memoryStore) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.items, k)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok || item.expired(time.Now()) {
+		m.put(key, []byte("1"), ttl)
+		return 1, nil
+	}
+
+	n, err := strconv.ParseInt(string(item.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	n++
+	item.value = []byte(strconv.FormatInt(n, 10))
+	m.items[key] = item
+	return n, nil
+}
+
+// put stores an item and drops expired entries at most once a minute so the map cannot grow
+// without bound; the caller must hold the lock
+func (m *memoryStore) put(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+	m.items[key] = item
+
+	if now.Sub(m.lastSweep) > time.Minute {
+		for k, i := range m.items {
+			if i.expired(now) {
+				delete(m.items, k)
+			}
+		}
+		m.lastSweep = now
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreExpires(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	if err := s.Set(ctx, "short", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "forever", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.Get(ctx, "short"); !ok {
+		t.Fatal("short missing before its ttl")
+	}
+	if n, _ := s.Incr(ctx, "count", 20*time.Millisecond); n != 1 {
+		t.Fatalf("first incr = %d", n)
+	}
+	if n, _ := s.Incr(ctx, "count", 20*time.Millisecond); n != 2 {
+		t.Fatalf("second incr = %d", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok, _ := s.Get(ctx, "short"); ok {
+		t.Fatal("short still there after its ttl")
+	}
+	if _, ok, _ := s.Get(ctx, "forever"); !ok {
+		t.Fatal("an entry without ttl expired")
+	}
+	if n, _ := s.Incr(ctx, "count", 20*time.Millisecond); n != 1 {
+		t.Fatalf("incr after expiry = %d, want a new counter", n)
+	}
+}
+
+func TestMemoryStoreCopiesValues(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	in := []byte("abc")
+	_ = s.Set(ctx, "k", in, 0)
+	in[0] = 'x'
+	out, _, _ := s.Get(ctx, "k")
+	out[1] = 'y'
+
+	if again, _, _ := s.Get(ctx, "k"); string(again) != "abc" {
+		t.Fatalf("got %q, want the value as set", again)
+	}
+}
+
+func TestMemoryStoreDeletePrefixIsLiteral(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, k := range []string{"q[1]:a", "q1:a", "q*:b", "qx:b", "other"} {
+		_ = s.Set(ctx, k, []byte("v"), 0)
+	}
+
+	_ = s.DeletePrefix(ctx, "q[1]")
+	_ = s.DeletePrefix(ctx, "q*")
+
+	for k, want := range map[string]bool{"q[1]:a": false, "q*:b": false, "q1:a": true, "qx:b": true, "other": true} {
+		if _, ok, _ := s.Get(ctx, k); ok != want {
+			t.Errorf("%s present = %t, want %t", k, ok, want)
+		}
+	}
+}
+
+func TestNewStoreWithoutRedisIsolatesNamespaces(t *testing.T) {
+	ctx := context.Background()
+	search, fx := NewStore(nil, "search"), NewStore(nil, "fx")
+
+	_ = search.Set(ctx, "k", []byte("search"), 0)
+	_ = fx.DeletePrefix(ctx, "")
+	if v, ok, _ := search.Get(ctx, "k"); !ok || string(v) != "search" {
+		t.Fatalf("got %q %t after clearing another namespace", v, ok)
+	}
+	if _, ok, _ := fx.Get(ctx, "k"); ok {
+		t.Fatal("fx sees the search namespace")
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const scanBatchSize = 500
+
+type redisStore struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewRedisStore stores keys as "<namespace>:<key>"
+func NewRedisStore(rdb *redis.Client, namespace string) Store {
+	return &redisStore{
+		rdb:    rdb,
+		prefix: namespace + ":",
+	}
+}
+
+func (r *redisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := r.rdb.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (r *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.rdb.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = r.prefix + k
+	}
+	return r.rdb.Del(ctx, full...).Err()
+}
+
+// DeletePrefix walks the namespace with SCAN rather than KEYS so large namespaces do not block redis
+func (r *redisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	match := escapeGlob(r.prefix+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := r.rdb.Scan(ctx, cursor, match, scanBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := r.rdb.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (r *redisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := r.rdb.Incr(ctx, r.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 && ttl > 0 {
+		if err := r.rdb.Expire(ctx, r.prefix+key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// escapeGlob quotes the characters SCAN MATCH treats as patterns
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis answers the few commands redisStore sends from a map, without a server; keys
+// containing "/" are not supported by its SCAN MATCH
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func newFakeRedis() (*redis.Client, *fakeRedis) {
+	f := &fakeRedis{data: make(map[string]string)}
+	rdb := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	rdb.AddHook(f)
+	return rdb, f
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, fmt.Errorf("fake redis does not dial")
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return fmt.Errorf("fake redis has no pipelines")
+	}
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		args := cmd.Args()
+		switch c := cmd.(type) {
+		case *redis.StringCmd: // GET
+			v, ok := f.data[args[1].(string)]
+			if !ok {
+				c.SetErr(redis.Nil)
+				return redis.Nil
+			}
+			c.SetVal(v)
+		case *redis.StatusCmd: // SET
+			f.data[args[1].(string)] = string(args[2].([]byte))
+			c.SetVal("OK")
+		case *redis.IntCmd: // DEL, UNLINK
+			var n int64
+			for _, k := range args[1:] {
+				if _, ok := f.data[k.(string)]; ok {
+					delete(f.data, k.(string))
+					n++
+				}
+			}
+			c.SetVal(n)
+		case *redis.ScanCmd: // SCAN 0 MATCH pattern COUNT n, answered in one page
+			var keys []string
+			for k := range f.data {
+				if ok, _ := path.Match(args[3].(string), k); ok {
+					keys = append(keys, k)
+				}
+			}
+			c.SetVal(keys, 0)
+		default:
+			return fmt.Errorf("fake redis does not support %v", args[0])
+		}
+		return nil
+	}
+}
+
+func (f *fakeRedis) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, 0, len(f.data))
+	for k := range f.data {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestRedisStoreNamespaces(t *testing.T) {
+	ctx := context.Background()
+	rdb, fake := newFakeRedis()
+	search, fx := NewRedisStore(rdb, "search"), NewRedisStore(rdb, "fx")
+
+	for _, s := range []Store{search, fx} {
+		if err := s.Set(ctx, "k", []byte(fmt.Sprint(s == search)), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fake.keys(); len(got) != 2 || got[0] != "fx:k" || got[1] != "search:k" {
+		t.Fatalf("stored keys %v, want [fx:k search:k]", got)
+	}
+	if v, ok, err := search.Get(ctx, "k"); err != nil || !ok || string(v) != "true" {
+		t.Fatalf("search k = %q %t %v", v, ok, err)
+	}
+
+	if err := search.DeletePrefix(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := search.Get(ctx, "k"); ok {
+		t.Fatal("search k survived clearing its namespace")
+	}
+	if v, ok, err := fx.Get(ctx, "k"); err != nil || !ok || string(v) != "false" {
+		t.Fatalf("fx k = %q %t %v after clearing search", v, ok, err)
+	}
+}
+
+func TestRedisStoreDeletePrefixIsLiteral(t *testing.T) {
+	ctx := context.Background()
+	rdb, fake := newFakeRedis()
+	s := NewRedisStore(rdb, "ns")
+
+	for _, k := range []string{"q[1]:a", "q1:a", "q*:b", "qx:b", `q\:c`, "other"} {
+		if err := s.Set(ctx, k, []byte("v"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, prefix := range []string{"q[1]", "q*", `q\`} {
+		if err := s.DeletePrefix(ctx, prefix); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"ns:other", "ns:q1:a", "ns:qx:b"}
+	got := fake.keys()
+	if len(got) != len(want) {
+		t.Fatalf("left %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("left %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	tests := map[string]string{
+		"plain:key": "plain:key",
+		"a*b":       `a\*b`,
+		"a?b":       `a\?b`,
+		"[ab]":      `\[ab\]`,
+		`a\b`:       `a\\b`,
+	}
+	for in, want := range tests {
+		if got := escapeGlob(in); got != want {
+			t.Errorf("escapeGlob(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"stopover.backend/internal/models"
+)
+
+// SearchResultCache stores aggregated search results under a normalized query key
+type SearchResultCache interface {
+	Get(ctx context.Context, key string) (*models.FlightSearchResult, bool, error)
+	Set(ctx context.Context, key string, result *models.FlightSearchResult, ttl time.Duration) error
+}
+
+// SearchKey hashes the parts of a query that change its results. The user IP is left out
+// so identical searches from different users share one entry; the version prefix lets a
+// change to the cached shape be rolled out without reading stale entries.
+func SearchKey(q models.FlightQuery) string {
+	var b strings.Builder
+	for _, s := range q.Segments {
+		fmt.Fprintf(&b, "%s-%s@%s/", strings.ToUpper(s.Origin), strings.ToUpper(s.Destination), s.Date)
+	}
+	fmt.Fprintf(&b, "a%d.c%d.i%d/%s/%s", q.Adults, q.Children, q.Infants,
+		strings.ToUpper(q.TripClass), strings.ToLower(q.Locale))
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return "v1:" + hex.EncodeToString(sum[:])
+}
+
+// NewSearchResultCache stores results JSON encoded in the given store
+func NewSearchResultCache(store Store) SearchResultCache {
+	return NewTyped[*models.FlightSearchResult](store, JSONCodec)
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+
+	"stopover.backend/internal/models"
+)
+
+func TestSearchKey(t *testing.T) {
+	base := models.FlightQuery{
+		Segments:  []models.QuerySegment{{Origin: "DEL", Destination: "BOM", Date: "2025-10-01"}, {Origin: "BOM", Destination: "DEL", Date: "2025-10-08"}},
+		Adults:    2,
+		TripClass: "Y",
+		Locale:    "en",
+		UserIP:    "10.0.0.1",
+	}
+	with := func(change func(q *models.FlightQuery)) models.FlightQuery {
+		q := base
+		q.Segments = append([]models.QuerySegment(nil), base.Segments...)
+		change(&q)
+		return q
+	}
+
+	tests := []struct {
+		name string
+		q    models.FlightQuery
+		same bool
+	}{
+		{name: "other user", q: with(func(q *models.FlightQuery) { q.UserIP = "192.0.2.7" }), same: true},
+		{name: "lower case codes", q: with(func(q *models.FlightQuery) { q.Segments[0].Origin, q.Segments[1].Destination = "del", "Del" }), same: true},
+		{name: "lower case class, upper case locale", q: with(func(q *models.FlightQuery) { q.TripClass, q.Locale = "y", "EN" }), same: true},
+		{name: "segments swapped", q: with(func(q *models.FlightQuery) { q.Segments[0], q.Segments[1] = q.Segments[1], q.Segments[0] }), same: false},
+		{name: "other date", q: with(func(q *models.FlightQuery) { q.Segments[1].Date = "2025-10-09" }), same: false},
+		{name: "adult becomes child", q: with(func(q *models.FlightQuery) { q.Adults, q.Children = 1, 1 }), same: false},
+		{name: "other class", q: with(func(q *models.FlightQuery) { q.TripClass = "C" }), same: false},
+		{name: "one way", q: with(func(q *models.FlightQuery) { q.Segments = q.Segments[:1] }), same: false},
+	}
+	want := SearchKey(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SearchKey(tt.q); (got == want) != tt.same {
+				t.Errorf("same key = %t, want %t", got == want, tt.same)
+			}
+		})
+	}
+}
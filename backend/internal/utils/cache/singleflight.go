@@ -0,0 +1,43 @@
+package cache
+
+import "sync"
+
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Group coalesces concurrent calls with the same key into a single execution
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do runs fn once for all concurrent callers of key and hands every caller the same result.
+// shared reports whether the result was produced by another caller's execution.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupCoalescesConcurrentCalls(t *testing.T) {
+	var g Group
+	var runs, shared atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+
+	fn := func() (interface{}, error) {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "v", nil
+	}
+
+	var wg sync.WaitGroup
+	call := func() {
+		defer wg.Done()
+		v, err, s := g.Do("k", fn)
+		if v != "v" || err != nil {
+			t.Errorf("got %v, %v", v, err)
+		}
+		if s {
+			shared.Add(1)
+		}
+	}
+
+	wg.Add(1)
+	go call()
+	<-started
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go call()
+	}
+	// give the late callers time to join the call in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 || shared.Load() != 9 {
+		t.Fatalf("ran %d times with %d shared results, want 1 run shared by 9", runs.Load(), shared.Load())
+	}
+
+	// the key is free again once the call returns
+	if _, _, s := g.Do("k", func() (interface{}, error) { return "again", nil }); s {
+		t.Fatal("a later call reused a finished result")
+	}
+}
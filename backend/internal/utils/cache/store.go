@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is a byte-level key/value cache scoped to one namespace. Keys passed to a store are
+// relative to its namespace, so DeletePrefix can never reach another namespace's data.
+// A ttl of zero means the entry does not expire.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Incr atomically increments a counter, starting its ttl when the counter is created
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// NewStore returns a redis backed store when a client is given and an in-memory one otherwise
+func NewStore(rdb *redis.Client, namespace string) Store {
+	if rdb == nil {
+		return NewMemoryStore()
+	}
+	return NewRedisStore(rdb, namespace)
+}
+
+// Codec converts cached values to and from bytes
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// JSONCodec encodes values as JSON
+var JSONCodec Codec = jsonCodec{}
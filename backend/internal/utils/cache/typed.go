@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Typed wraps a Store with a codec so callers work with values instead of bytes
+type Typed[T any] struct {
+	store Store
+	codec Codec
+	group Group
+}
+
+func NewTyped[T any](store Store, codec Codec) *Typed[T] {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &Typed[T]{
+		store: store,
+		codec: codec,
+	}
+}
+
+// Get returns the decoded value, or false when the key is missing or expired
+func (t *Typed[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var v T
+	data, ok, err := t.store.Get(ctx, key)
+	if err != nil || !ok {
+		return v, false, err
+	}
+	if err := t.codec.Unmarshal(data, &v); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+func (t *Typed[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return t.store.Set(ctx, key, data, ttl)
+}
+
+func (t *Typed[T]) Delete(ctx context.Context, keys ...string) error {
+	return t.store.Delete(ctx, keys...)
+}
+
+func (t *Typed[T]) DeletePrefix(ctx context.Context, prefix string) error {
+	return t.store.DeletePrefix(ctx, prefix)
+}
+
+// GetOrLoad returns the cached value or calls load once for all concurrent callers of the
+// same key and caches its result. Cache errors are logged and treated as a miss.
+func (t *Typed[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	if v, ok, err := t.Get(ctx, key); err != nil {
+		log.Printf("[Typed.GetOrLoad] get %s: %v", key, err)
+	} else if ok {
+		return v, nil
+	}
+
+	res, err, _ := t.group.Do(key, func() (interface{}, error) {
+		v, err := load(ctx)
+		if err != nil {
+			return v, err
+		}
+		if err := t.Set(ctx, key, v, ttl); err != nil {
+			log.Printf("[Typed.GetOrLoad] set %s: %v", key, err)
+		}
+		return v, nil
+	})
+	v, _ := res.(T)
+	return v, err
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type item struct {
+	Name string `json:"name"`
+}
+
+// failingCodec fails to marshal, as a value the codec cannot represent would
+type failingCodec struct{}
+
+func (failingCodec) Marshal(v interface{}) ([]byte, error)      { return nil, errors.New("cannot marshal") }
+func (failingCodec) Unmarshal(data []byte, v interface{}) error { return JSONCodec.Unmarshal(data, v) }
+
+func TestTypedGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	loads := 0
+	load := func(ctx context.Context) (item, error) {
+		loads++
+		return item{Name: "loaded"}, nil
+	}
+
+	t.Run("undecodable entry is a miss and is replaced", func(t *testing.T) {
+		loads = 0
+		store := NewMemoryStore()
+		_ = store.Set(ctx, "k", []byte("{not json"), 0)
+		typed := NewTyped[item](store, JSONCodec)
+
+		for i := 0; i < 2; i++ {
+			v, err := typed.GetOrLoad(ctx, "k", 0, load)
+			if err != nil || v.Name != "loaded" {
+				t.Fatalf("got %+v, %v", v, err)
+			}
+		}
+		if loads != 1 {
+			t.Fatalf("loaded %d times, want once", loads)
+		}
+	})
+
+	t.Run("unencodable value is returned but not cached", func(t *testing.T) {
+		loads = 0
+		typed := NewTyped[item](NewMemoryStore(), failingCodec{})
+
+		for i := 0; i < 2; i++ {
+			v, err := typed.GetOrLoad(ctx, "k", 0, load)
+			if err != nil || v.Name != "loaded" {
+				t.Fatalf("got %+v, %v", v, err)
+			}
+		}
+		if loads != 2 {
+			t.Fatalf("loaded %d times, want every time", loads)
+		}
+	})
+
+	t.Run("load error is returned and not cached", func(t *testing.T) {
+		store := NewMemoryStore()
+		typed := NewTyped[item](store, JSONCodec)
+		boom := errors.New("boom")
+
+		_, err := typed.GetOrLoad(ctx, "k", 0, func(ctx context.Context) (item, error) { return item{}, boom })
+		if !errors.Is(err, boom) {
+			t.Fatalf("got %v, want the load error", err)
+		}
+		if _, ok, _ := store.Get(ctx, "k"); ok {
+			t.Fatal("a failed load was cached")
+		}
+	})
+}
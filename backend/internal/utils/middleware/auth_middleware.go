@@ -14,6 +14,7 @@ import (
 type AuthRepo interface {
 	AuthUser(s jwtutil.TokenRepo) gin.HandlerFunc
 	OptionalAuthUser(s jwtutil.TokenRepo) gin.HandlerFunc
+	RequireRole(roles ...common.UserRole) gin.HandlerFunc
 }
 
 type auth struct {
@@ -49,6 +50,26 @@ func (au *auth) OptionalAuthUser(tokenRepo jwtutil.TokenRepo) gin.HandlerFunc {
 	}
 }
 
+// RequireRole must run after AuthUser and lets only the given roles through
+func (au *auth) RequireRole(roles ...common.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := common.GetUserFromContext(c.Request.Context())
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+		for _, r := range roles {
+			if common.UserRole(user.RoleId) == r {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.Abort()
+	}
+}
+
 // Helpers
 
 func parseAndValidateToken(c *gin.Context, tokenRepo jwtutil.TokenRepo) (*models.User, error) {
@@ -0,0 +1,154 @@
+package aviasales
+
+import (
+	"sync"
+)
+
+// ResultAccumulator merges result chunks from one or more polls of the same search.
+// Proposals are de-duplicated by Sign; when a known proposal shows up again its terms
+// are merged so fares from every agency are kept. Every addition or merge bumps the version,
+// so readers can ask for what changed since the version they last saw.
+type ResultAccumulator struct {
+	mu        sync.RWMutex
+	searchID  string
+	proposals []Proposal
+	versions  []int // the version each proposal was added or last merged at
+	version   int
+	index     map[string]int
+	airports  map[string]Airport
+	airlines  map[string]Airline
+	currency  string
+	complete  bool
+}
+
+func NewResultAccumulator(searchID string) *ResultAccumulator {
+	return &ResultAccumulator{
+		searchID:  searchID,
+		proposals: make([]Proposal, 0),
+		index:     make(map[string]int),
+		airports:  make(map[string]Airport),
+		airlines:  make(map[string]Airline),
+	}
+}
+
+// Add merges a chunk and returns the number of proposals that were new
+func (a *ResultAccumulator) Add(chunk *FlightSearchResponseWrapper) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	added := 0
+	for _, p := range chunk.Proposals {
+		if p.Sign != "" {
+			if i, ok := a.index[p.Sign]; ok {
+				if mergeTerms(&a.proposals[i], p.Terms) {
+					a.version++
+					a.versions[i] = a.version
+				}
+				continue
+			}
+			a.index[p.Sign] = len(a.proposals)
+		}
+		a.version++
+		a.proposals = append(a.proposals, p)
+		a.versions = append(a.versions, a.version)
+		added++
+	}
+	for k, v := range chunk.Airports {
+		a.airports[k] = v
+	}
+	for k, v := range chunk.Airlines {
+		a.airlines[k] = v
+	}
+	if chunk.Currency != "" {
+		a.currency = chunk.Currency
+	}
+	if chunk.SearchID != "" && a.searchID == "" {
+		a.searchID = chunk.SearchID
+	}
+	if chunk.Complete {
+		a.complete = true
+	}
+	return added
+}
+
+// Complete reports whether the terminal completion marker has been seen
+func (a *ResultAccumulator) Complete() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.complete
+}
+
+// Len returns the number of distinct proposals accumulated so far
+func (a *ResultAccumulator) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.proposals)
+}
+
+// Version identifies the current state; it grows whenever a proposal is added or merged
+func (a *ResultAccumulator) Version() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.version
+}
+
+// Result returns a copy of everything accumulated so far
+func (a *ResultAccumulator) Result() *FlightSearchResponseWrapper {
+	res, _ := a.Since(0)
+	return res
+}
+
+// Since returns a copy of the proposals added or merged after version, in the order they were
+// first seen, together with all metadata and the version the copy is current as of. A proposal
+// that gained terms is returned again in full.
+func (a *ResultAccumulator) Since(version int) (*FlightSearchResponseWrapper, int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	res := &FlightSearchResponseWrapper{
+		Proposals: make([]Proposal, 0),
+		SearchID:  a.searchID,
+		Airports:  make(map[string]Airport, len(a.airports)),
+		Airlines:  make(map[string]Airline, len(a.airlines)),
+		Currency:  a.currency,
+		Complete:  a.complete,
+	}
+	for i, p := range a.proposals {
+		if a.versions[i] > version {
+			res.Proposals = append(res.Proposals, p)
+		}
+	}
+	for k, v := range a.airports {
+		res.Airports[k] = v
+	}
+	for k, v := range a.airlines {
+		res.Airlines[k] = v
+	}
+	return res, a.version
+}
+
+// mergeTerms adds the terms of agencies the proposal does not have yet and reports whether
+// there were any
+func mergeTerms(p *Proposal, terms map[string]TermData) bool {
+	changed := false
+	for k := range terms {
+		if _, ok := p.Terms[k]; !ok {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return false
+	}
+	merged := make(map[string]TermData, len(p.Terms)+len(terms))
+	for k, v := range p.Terms {
+		merged[k] = v
+	}
+	for k, v := range terms {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+	p.Terms = merged
+	return true
+}
@@ -0,0 +1,79 @@
+package aviasales
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func proposal(sign string, gates ...string) Proposal {
+	p := Proposal{Sign: sign, Terms: make(map[string]TermData)}
+	for _, g := range gates {
+		p.Terms[g] = TermData{Currency: "usd", UnifiedPrice: 100}
+	}
+	return p
+}
+
+// summary lists each proposal with its gates, e.g. "a:g1+g2"
+func summary(res *FlightSearchResponseWrapper) string {
+	var out []string
+	for _, p := range res.Proposals {
+		var gates []string
+		for g := range p.Terms {
+			gates = append(gates, g)
+		}
+		sort.Strings(gates)
+		out = append(out, p.Sign+":"+strings.Join(gates, "+"))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestResultAccumulatorSinceReturnsMergedProposals(t *testing.T) {
+	acc := NewResultAccumulator("search")
+
+	steps := []struct {
+		name      string
+		chunk     []Proposal
+		wantAdded int
+		want      string
+	}{
+		{name: "first poll", chunk: []Proposal{proposal("a", "g1"), proposal("b", "g1")}, wantAdded: 2, want: "a:g1 b:g1"},
+		{name: "known proposal from another gate", chunk: []Proposal{proposal("b", "g2")}, wantAdded: 0, want: "b:g1+g2"},
+		{name: "nothing new", chunk: []Proposal{proposal("a", "g1"), proposal("b", "g2")}, wantAdded: 0, want: ""},
+		{name: "new and merged together", chunk: []Proposal{proposal("c", "g1"), proposal("a", "g3")}, wantAdded: 1, want: "a:g1+g3 c:g1"},
+	}
+	version := 0
+	for _, step := range steps {
+		before := acc.Version()
+		if added := acc.Add(&FlightSearchResponseWrapper{Proposals: step.chunk}); added != step.wantAdded {
+			t.Fatalf("%s: added %d, want %d", step.name, added, step.wantAdded)
+		}
+		if step.want == "" && acc.Version() != before {
+			t.Fatalf("%s: version moved without a change", step.name)
+		}
+
+		res, current := acc.Since(version)
+		if got := summary(res); got != step.want {
+			t.Fatalf("%s: since %d got %q, want %q", step.name, version, got, step.want)
+		}
+		version = current
+	}
+
+	if got := summary(acc.Result()); got != "a:g1+g3 b:g1+g2 c:g1" {
+		t.Fatalf("result %q", got)
+	}
+	if acc.Len() != 3 {
+		t.Fatalf("len %d, want 3", acc.Len())
+	}
+}
+
+func TestResultAccumulatorMergeDoesNotTouchEarlierCopies(t *testing.T) {
+	acc := NewResultAccumulator("search")
+	acc.Add(&FlightSearchResponseWrapper{Proposals: []Proposal{proposal("a", "g1")}})
+	before := acc.Result()
+
+	acc.Add(&FlightSearchResponseWrapper{Proposals: []Proposal{proposal("a", "g2")}})
+	if got := summary(before); got != "a:g1" {
+		t.Fatalf("earlier copy changed to %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+package aviasales
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the supplier while the breaker is open
+var ErrCircuitOpen = errors.New("aviasales: circuit breaker open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreaker opens after a run of consecutive failures and rejects calls until the cooldown
+// has passed. It then lets a single trial call through; success closes it, failure reopens it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a breaker; a threshold of zero or less disables it
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may go through
+func (b *CircuitBreaker) Allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		// a trial call is already in flight
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Success records a healthy response and closes the breaker
+func (b *CircuitBreaker) Success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// Failure records a failed call and opens the breaker once the threshold is reached
+func (b *CircuitBreaker) Failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Abort gives up a trial call without judging the supplier, e.g. when the caller went away,
+// so the next call can run the trial instead
+func (b *CircuitBreaker) Abort() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		b.openedAt = b.now().Add(-b.cooldown)
+	}
+}
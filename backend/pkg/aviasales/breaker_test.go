@@ -0,0 +1,82 @@
+package aviasales
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		do      func()
+		advance time.Duration
+		want    error
+	}{
+		{name: "closed allows", want: nil},
+		{name: "one failure stays closed", do: b.Failure, want: nil},
+		{name: "threshold opens", do: b.Failure, want: ErrCircuitOpen},
+		{name: "open during cooldown", advance: 59 * time.Second, want: ErrCircuitOpen},
+		{name: "half-open after cooldown lets a probe through", advance: time.Second, want: nil},
+		{name: "half-open rejects a second caller", want: ErrCircuitOpen},
+		{name: "failed probe reopens", do: b.Failure, want: ErrCircuitOpen},
+		{name: "reopened for a full cooldown", advance: 59 * time.Second, want: ErrCircuitOpen},
+		{name: "half-open again", advance: time.Second, want: nil},
+		{name: "successful probe closes", do: b.Success, want: nil},
+		{name: "closed after success", want: nil},
+		{name: "failures counted from zero", do: b.Failure, want: nil},
+	}
+	for _, s := range steps {
+		if s.do != nil {
+			s.do()
+		}
+		now = now.Add(s.advance)
+		if err := b.Allow(); !errors.Is(err, s.want) {
+			t.Fatalf("%s: Allow() = %v, want %v", s.name, err, s.want)
+		}
+	}
+}
+
+func TestCircuitBreakerAbortFreesTheProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	b.Abort()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe after abort rejected: %v", err)
+	}
+}
+
+func TestResilientTransportOpensBreaker(t *testing.T) {
+	srv, calls := scriptedServer(t, status(500))
+	tr, _ := newTestTransport(TransportConfig{MaxRetries: -1, BreakerThreshold: 2, BreakerCooldown: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		resp, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("calls = %d, want the open breaker to stop the third", got)
+	}
+}
@@ -0,0 +1,344 @@
+package aviasales
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// cassette modes for AviaSalesConfig.CassetteMode
+const (
+	CassetteOff    = ""
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+const redacted = "REDACTED"
+
+// fields that identify the partner or the user and never belong in a cassette
+var (
+	redactedBodyFields  = []string{"signature", "user_ip", "marker", "token"}
+	redactedQueryParams = []string{"token", "signature", "marker", "user_ip"}
+	redactedHeaders     = []string{"Authorization", "X-Access-Token", "Cookie", "Set-Cookie"}
+)
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Key      string           `json:"key"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body"`
+}
+
+// NewCassetteTransport wraps base according to mode: record writes every exchange to dir,
+// replay answers from dir without touching the network, and anything else returns base.
+func NewCassetteTransport(mode, dir string, base http.RoundTripper) http.RoundTripper {
+	switch mode {
+	case CassetteRecord:
+		return NewRecordingTransport(dir, base)
+	case CassetteReplay:
+		rt, err := NewReplayTransport(dir)
+		if err != nil {
+			log.Printf("[NewCassetteTransport] replay disabled: %v", err)
+			return failingTransport{err: err}
+		}
+		return rt
+	}
+	return base
+}
+
+// RecordingTransport forwards requests and saves each exchange, redacted, as one file in a
+// cassette directory. Compressed responses are stored and returned decompressed.
+type RecordingTransport struct {
+	dir  string
+	base http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+func NewRecordingTransport(dir string, base http.RoundTripper) *RecordingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	seq := 0
+	if entries, err := os.ReadDir(dir); err == nil {
+		seq = len(entries)
+	}
+	return &RecordingTransport{dir: dir, base: base, seq: seq}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		if respBody, err = decompress(enc, respBody); err != nil {
+			return nil, err
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.ContentLength = int64(len(respBody))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+			Body:    string(redactJSON(reqBody)),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+			Body:    string(redactJSON(respBody)),
+		},
+	}
+	in.Key = interactionKey(req.Method, req.URL, []byte(in.Request.Body))
+
+	if err := t.save(in); err != nil {
+		log.Printf("[RecordingTransport] failed to save interaction: %v", err)
+	}
+	return resp, nil
+}
+
+func (t *RecordingTransport) save(in Interaction) error {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.seq++
+	name := fmt.Sprintf("%06d_%s.json", t.seq, in.Key[:12])
+	t.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(t.dir, name), data, 0o644)
+}
+
+// ReplayTransport serves recorded interactions. Requests are matched on method, redacted URL and
+// redacted body; identical requests, such as repeated result polls, get their recordings in the
+// order they were made, and the last one is repeated once they run out.
+type ReplayTransport struct {
+	mu     sync.Mutex
+	tapes  map[string][]Interaction
+	served map[string]int
+}
+
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no cassettes in %s", dir)
+	}
+	sort.Strings(files)
+
+	rt := &ReplayTransport{
+		tapes:  make(map[string][]Interaction),
+		served: make(map[string]int),
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var in Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		rt.tapes[in.Key] = append(rt.tapes[in.Key], in)
+	}
+	return rt, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := interactionKey(req.Method, req.URL, redactJSON(body))
+
+	t.mu.Lock()
+	tape := t.tapes[key]
+	if len(tape) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("aviasales: no recorded interaction for %s %s", req.Method, redactURL(req.URL))
+	}
+	i := t.served[key]
+	if i >= len(tape) {
+		i = len(tape) - 1
+	}
+	t.served[key]++
+	in := tape[i]
+	t.mu.Unlock()
+
+	header := in.Response.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+type failingTransport struct{ err error }
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// readBody consumes a request body and puts back an identical reader
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// interactionKey ignores scheme and host so cassettes replay against any base url
+func interactionKey(method string, u *url.URL, body []byte) string {
+	c := *u
+	c.RawQuery = redactQuery(u)
+	h := sha256.New()
+	h.Write([]byte(method + " " + c.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	c.RawQuery = redactQuery(u)
+	return c.String()
+}
+
+func redactQuery(u *url.URL) string {
+	q := u.Query()
+	for _, p := range redactedQueryParams {
+		if q.Has(p) {
+			q.Set(p, redacted)
+		}
+	}
+	return q.Encode()
+}
+
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range redactedHeaders {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// redactJSON masks identifying fields of a JSON object or array of objects, at any depth, and the
+// identifying query params of string values that are urls, such as booking links carrying the
+// marker. Bodies that are not JSON are returned unchanged.
+func redactJSON(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if contains(redactedBodyFields, k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	case string:
+		if u, err := url.Parse(t); err == nil && u.IsAbs() && u.RawQuery != "" {
+			return redactURL(u)
+		}
+	}
+	return v
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func decompress(encoding string, data []byte) ([]byte, error) {
+	r, err := decodeContent(encoding, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
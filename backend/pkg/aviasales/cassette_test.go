@@ -0,0 +1,165 @@
+package aviasales_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"stopover.backend/config"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/aviasales/fake"
+)
+
+var record = flag.Bool("record", false, "re-record the testdata cassettes from the fake server")
+
+const cassetteDir = "testdata/cassettes/search_del_cok"
+
+// partner credentials and user data the cassette was recorded with; none of them may reach disk
+const (
+	recordToken  = "rec-token-4f1d"
+	recordMarker = "rec-marker-9921"
+	recordUserIP = "203.0.113.7"
+)
+
+var cassetteRequest = aviasales.FlightSearchRequest{
+	Locale:     "en",
+	TripClass:  "Y",
+	Passengers: aviasales.PassengerInfo{Adults: 1},
+	Segments:   []aviasales.Segment{{Origin: "DEL", Destination: "COK", Date: "2025-10-01"}},
+}
+
+// searchAndBook runs a search to completion and resolves the booking link of its first term
+func searchAndBook(t *testing.T, client aviasales.FlightIntegrationAPI, userIP string) (*aviasales.FlightSearchResponseWrapper, *aviasales.BookingLink) {
+	t.Helper()
+	ctx := context.Background()
+
+	req := cassetteRequest
+	req.UserIP = userIP
+	init, err := client.InitSearch(ctx, req)
+	if err != nil {
+		t.Fatalf("InitSearch: %v", err)
+	}
+	result, err := client.GetSearchResultsWithPolling(ctx, init.SearchID, 5, time.Millisecond)
+	if err != nil {
+		t.Fatalf("GetSearchResultsWithPolling: %v", err)
+	}
+	if len(result.Proposals) == 0 {
+		t.Fatal("search returned no proposals")
+	}
+
+	// the lowest gate, so replays book the term that was recorded
+	terms := result.Proposals[0].Terms
+	gates := make([]string, 0, len(terms))
+	for gate := range terms {
+		gates = append(gates, gate)
+	}
+	sort.Strings(gates)
+	term := terms[gates[0]]
+	link, err := client.GetBookingLink(ctx, init.SearchID, term.URL.String())
+	if err != nil {
+		t.Fatalf("GetBookingLink: %v", err)
+	}
+	return result, link
+}
+
+func cassetteClient(mode, token, marker, baseURL string) aviasales.FlightIntegrationAPI {
+	cfg := &config.Config{AviaSalesConfig: fake.Config(baseURL)}
+	cfg.AviaSalesConfig.CassetteMode = mode
+	cfg.AviaSalesConfig.CassetteDir = cassetteDir
+	return aviasales.NewFlightIntegrationClient(token, marker, "stopover.test", cfg)
+}
+
+func recordCassette(t *testing.T) {
+	if err := os.RemoveAll(cassetteDir); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(fake.New(fake.Options{Token: recordToken, Polls: 2, Gzip: true}))
+	defer srv.Close()
+	searchAndBook(t, cassetteClient(aviasales.CassetteRecord, recordToken, recordMarker, srv.URL), recordUserIP)
+}
+
+func TestCassetteReplay(t *testing.T) {
+	if *record {
+		recordCassette(t)
+	}
+
+	// the base url points nowhere: replay must not touch the network
+	result, link := searchAndBook(t, cassetteClient(aviasales.CassetteReplay, recordToken, recordMarker, "http://127.0.0.1:1"), recordUserIP)
+	if !result.Complete {
+		t.Error("replayed result is not complete")
+	}
+	if want := len(fake.DefaultFixture().Offers); len(result.Proposals) != want {
+		t.Errorf("replayed proposals = %d, want %d", len(result.Proposals), want)
+	}
+	if link.URL == "" {
+		t.Error("replayed booking link has no url")
+	}
+}
+
+func TestCassetteMatchKeyIgnoresCredentials(t *testing.T) {
+	// different token, marker and user ip change the signature and every identifying field of the
+	// requests; they still match the recording because the key is built from the redacted request
+	client := cassetteClient(aviasales.CassetteReplay, "another-token", "another-marker", "http://127.0.0.1:1")
+	result, _ := searchAndBook(t, client, "198.51.100.23")
+	if !result.Complete {
+		t.Error("replayed result is not complete")
+	}
+}
+
+func TestCassetteIsRedactedOnDisk(t *testing.T) {
+	req := cassetteRequest
+	signature := aviasales.GenerateSignature(recordToken, recordMarker, "stopover.test", req.Locale, req.TripClass,
+		recordUserIP, req.Passengers, req.Segments)
+	secrets := map[string]string{
+		"token":     recordToken,
+		"marker":    recordMarker,
+		"user_ip":   recordUserIP,
+		"signature": signature,
+	}
+
+	files, err := filepath.Glob(filepath.Join(cassetteDir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no cassette files in %s: %v", cassetteDir, err)
+	}
+
+	var sawInit bool
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range secrets {
+			if strings.Contains(string(data), value) {
+				t.Errorf("%s: %s %q is not redacted", filepath.Base(f), name, value)
+			}
+		}
+
+		var in aviasales.Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if !strings.HasSuffix(strings.SplitN(in.Request.URL, "?", 2)[0], fake.InitPath) {
+			continue
+		}
+		sawInit = true
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(in.Request.Body), &body); err != nil {
+			t.Fatalf("%s: init body: %v", f, err)
+		}
+		for _, field := range []string{"signature", "marker", "user_ip"} {
+			if body[field] != "REDACTED" {
+				t.Errorf("%s: init %s = %v, want REDACTED", filepath.Base(f), field, body[field])
+			}
+		}
+	}
+	if !sawInit {
+		t.Error("cassette has no init request")
+	}
+}
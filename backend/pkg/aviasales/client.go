@@ -2,13 +2,13 @@ package aviasales
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
 
 	"stopover.backend/config"
@@ -27,16 +27,112 @@ func NewFlightIntegrationClient(token, marker, host string, config *config.Confi
 		Token:  token,
 		Marker: marker,
 		Host:   host,
-		HTTP:   &http.Client{Timeout: 15 * time.Second},
+		HTTP:   &http.Client{Transport: NewResilientTransport(baseTransport(config), transportConfig(config))},
 		Config: config,
 	}
 }
 
+// baseTransport is the transport under the retry layer, so every attempt is recorded or replayed
+func baseTransport(cfg *config.Config) http.RoundTripper {
+	if cfg == nil {
+		return http.DefaultTransport
+	}
+	ac := cfg.AviaSalesConfig
+	return NewCassetteTransport(ac.CassetteMode, ac.CassetteDir, http.DefaultTransport)
+}
+
+// GetBookingLink resolves the agency redirect for a term of a search. Links expire shortly after
+// they are issued, so this is called when the user clicks through rather than with the results.
+func (c *Client) GetBookingLink(ctx context.Context, searchID, termURL string) (*BookingLink, error) {
+	format := c.Config.AviaSalesConfig.BookingURL
+	if format == "" {
+		format = config.DefaultBookingURL
+	}
+	endpoint := fmt.Sprintf(format, url.PathEscape(searchID), url.PathEscape(termURL))
+	if c.Marker != "" {
+		endpoint += "?marker=" + url.QueryEscape(c.Marker)
+	}
+	log.Printf("[GetBookingLink] Resolving term %s of search %s", termURL, searchID)
+
+	httpReq, _ := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		log.Printf("[GetBookingLink] HTTP request failed: %v", err)
+		return nil, fmt.Errorf("booking link request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[GetBookingLink] Non-200 status: %d\nBody: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("booking link HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var link BookingLink
+	if err := json.Unmarshal(respBody, &link); err != nil {
+		log.Printf("[GetBookingLink] Failed to unmarshal response: %v", err)
+		return nil, fmt.Errorf("unmarshal booking link: %w", err)
+	}
+	if link.URL == "" {
+		return nil, fmt.Errorf("booking link for term %s has no url", termURL)
+	}
+	if link.Method == "" {
+		link.Method = http.MethodGet
+	}
+	return &link, nil
+}
+
+// readResults stream-decodes a results response, undoing its content encoding and enforcing the
+// body size limit on the decompressed bytes
+func (c *Client) readResults(resp *http.Response) (*FlightSearchResponseWrapper, error) {
+	body, err := decodeContent(resp.Header.Get("Content-Encoding"), resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		// error bodies are compressed like any other; decode them so the message is readable
+		var respBody []byte
+		if err == nil {
+			respBody, _ = io.ReadAll(io.LimitReader(body, 4<<10))
+			body.Close()
+		}
+		return nil, fmt.Errorf("search result HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return streamResultChunks(newLimitReader(body, c.maxBodyBytes()))
+}
+
+func (c *Client) maxBodyBytes() int64 {
+	if c.Config == nil {
+		return DefaultMaxBodyBytes
+	}
+	return c.Config.AviaSalesConfig.MaxBodyBytes
+}
+
+func transportConfig(cfg *config.Config) TransportConfig {
+	if cfg == nil {
+		return DefaultTransportConfig
+	}
+	ac := cfg.AviaSalesConfig
+	return TransportConfig{
+		MaxRetries:       ac.MaxRetries,
+		BaseDelay:        ac.RetryBaseDelay,
+		MaxDelay:         ac.RetryMaxDelay,
+		AttemptTimeout:   ac.AttemptTimeout,
+		BreakerThreshold: ac.BreakerThreshold,
+		BreakerCooldown:  ac.BreakerCooldown,
+	}
+}
+
 type FlightIntegrationAPI interface {
 	InitSearch(ctx context.Context, req FlightSearchRequest) (*FlightSearchInitResponse, error)
-	GetSearchResultsWithPolling(ctx context.Context, searchID string, maxAttempts int, pollInterval time.Duration) (*FlightSearchResponseWrapper, error)
-	GetSearchResultsRaw(ctx context.Context, searchID string) (*FlightSearchResponseWrapper, error)
-	GetSearchResults(ctx context.Context, searchID string) (*FlightSearchResponseWrapper, error)
+	GetSearchResultsWithPolling(ctx context.Context, searchID string, maxAttempts int, pollInterval time.Duration, processors ...Processor) (*FlightSearchResponseWrapper, error)
+	GetSearchResults(ctx context.Context, searchID string, processors ...Processor) (*FlightSearchResponseWrapper, error)
+	GetBookingLink(ctx context.Context, searchID, termURL string) (*BookingLink, error)
 }
 
 func (c *Client) InitSearch(ctx context.Context, req FlightSearchRequest) (*FlightSearchInitResponse, error) {
@@ -50,30 +146,10 @@ func (c *Client) InitSearch(ctx context.Context, req FlightSearchRequest) (*Flig
 		req.Marker = c.Marker
 	}
 
-	// Prepare map for signature
-	signParams := map[string]string{
-		"host":       req.Host,
-		"locale":     req.Locale,
-		"trip_class": req.TripClass,
-		"user_ip":    req.UserIP,
-	}
-
-	// Flatten passengers
-	signParams["passengers.adults"] = fmt.Sprintf("%d", req.Passengers.Adults)
-	signParams["passengers.children"] = fmt.Sprintf("%d", req.Passengers.Children)
-	signParams["passengers.infants"] = fmt.Sprintf("%d", req.Passengers.Infants)
-
-	// Flatten segments
-	for i, s := range req.Segments {
-		signParams[fmt.Sprintf("segments[%d].date", i)] = s.Date
-		signParams[fmt.Sprintf("segments[%d].origin", i)] = s.Origin
-		signParams[fmt.Sprintf("segments[%d].destination", i)] = s.Destination
-	}
-
 	// Generate signature
 	req.Signature = GenerateSignature(
 		c.Token,
-		c.Marker,
+		req.Marker,
 		req.Host,
 		req.Locale,
 		req.TripClass,
@@ -123,8 +199,9 @@ func (c *Client) InitSearch(ctx context.Context, req FlightSearchRequest) (*Flig
 	return &result, nil
 }
 
-// GetSearchResults fetches search results for a given search ID with a single request
-func (c *Client) GetSearchResults(ctx context.Context, searchID string) (*FlightSearchResponseWrapper, error) {
+// GetSearchResults fetches one poll of search results and runs them through the given processors.
+// Without processors the response is returned exactly as the supplier sent it.
+func (c *Client) GetSearchResults(ctx context.Context, searchID string, processors ...Processor) (*FlightSearchResponseWrapper, error) {
 	url := fmt.Sprintf(c.Config.AviaSalesConfig.ResultSearchURL, searchID)
 	log.Printf("[GetSearchResults] Fetching results from URL: %s", url)
 
@@ -143,133 +220,50 @@ func (c *Client) GetSearchResults(ctx context.Context, searchID string) (*Flight
 		}
 	}(resp.Body)
 
-	var reader io.Reader = resp.Body
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			log.Printf("[GetSearchResults] Failed to create gzip reader: %v", err)
-			return nil, err
-		}
-		defer func(gzipReader *gzip.Reader) {
-			err := gzipReader.Close()
-			if err != nil {
-				log.Printf("[GetSearchResults] Failed to close gzip reader: %v", err)
-			}
-		}(gzipReader)
-		reader = gzipReader
-	}
-
-	respBody, err := io.ReadAll(reader)
+	result, err := c.readResults(resp)
 	if err != nil {
-		log.Printf("[GetSearchResults] Failed to read response body: %v", err)
+		log.Printf("[GetSearchResults] Failed to read response: %v", err)
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[GetSearchResults] Non-200 status: %d\nBody: %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("search result HTTP %d: %s", resp.StatusCode, string(respBody))
-	}
 
-	var results []FlightSearchResponseWrapper
-	if err := json.Unmarshal(respBody, &results); err != nil {
-		log.Printf("[GetSearchResults] Failed to unmarshal response: %v", err)
+	received := len(result.Proposals)
+	if err := runPipeline(ctx, result, processors); err != nil {
+		log.Printf("[GetSearchResults] Processing failed: %v", err)
 		return nil, err
 	}
 
-	if len(results) == 0 {
-		log.Printf("[GetSearchResults] Empty result array")
-		return nil, fmt.Errorf("no results returned")
-	}
-
-	// Return results as-is, no conversion
-	log.Printf("[GetSearchResults] Search ID: %s, Offers: %d (prices in original currencies)", results[0].SearchID, len(results[0].Proposals))
-
-	return &results[0], nil
+	log.Printf("[GetSearchResults] Search ID: %s, Offers: %d of %d received, Complete: %t", result.SearchID, len(result.Proposals), received, result.Complete)
+	return result, nil
 }
 
-// GetSearchResultsRaw fetches search results without currency conversion for debugging
-func (c *Client) GetSearchResultsRaw(ctx context.Context, searchID string) (*FlightSearchResponseWrapper, error) {
-	url := fmt.Sprintf(c.Config.AviaSalesConfig.ResultSearchURL, searchID)
-	log.Printf("[GetSearchResultsRaw] Fetching raw results from URL: %s", url)
-
-	httpReq, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	httpReq.Header.Set("Accept-Encoding", "gzip,deflate")
-
-	resp, err := c.HTTP.Do(httpReq)
-	if err != nil {
-		log.Printf("[GetSearchResultsRaw] HTTP request failed: %v", err)
-		return nil, fmt.Errorf("get search result failed: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Printf("[GetSearchResultsRaw] Failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	var reader io.Reader = resp.Body
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzipReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			log.Printf("[GetSearchResultsRaw] Failed to create gzip reader: %v", err)
-			return nil, err
-		}
-		defer func(gzipReader *gzip.Reader) {
-			err := gzipReader.Close()
-			if err != nil {
-				log.Printf("[GetSearchResultsRaw] Failed to close gzip reader: %v", err)
-			}
-		}(gzipReader)
-		reader = gzipReader
-	}
-
-	respBody, err := io.ReadAll(reader)
-	if err != nil {
-		log.Printf("[GetSearchResultsRaw] Failed to read response body: %v", err)
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[GetSearchResultsRaw] Non-200 status: %d\nBody: %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("search result HTTP %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	var results []FlightSearchResponseWrapper
-	if err := json.Unmarshal(respBody, &results); err != nil {
-		log.Printf("[GetSearchResultsRaw] Failed to unmarshal response: %v", err)
-		return nil, err
-	}
-
-	if len(results) == 0 {
-		log.Printf("[GetSearchResultsRaw] Empty result array")
-		return nil, fmt.Errorf("no results returned")
-	}
-
-	// DO NOT convert prices - return raw data
-	log.Printf("[GetSearchResultsRaw] Search ID: %s, Offers: %d (RAW - no currency conversion)", results[0].SearchID, len(results[0].Proposals))
-
-	return &results[0], nil
-}
-
-// GetSearchResultsWithPolling fetches search results with polling until proposals are found or timeout is reached
-func (c *Client) GetSearchResultsWithPolling(ctx context.Context, searchID string, maxAttempts int, pollInterval time.Duration) (*FlightSearchResponseWrapper, error) {
+// GetSearchResultsWithPolling polls for search results, accumulating every chunk, until the API
+// sends its completion marker or the attempts are exhausted
+func (c *Client) GetSearchResultsWithPolling(ctx context.Context, searchID string, maxAttempts int, pollInterval time.Duration, processors ...Processor) (*FlightSearchResponseWrapper, error) {
 	log.Printf("[GetSearchResultsWithPolling] Starting polling for search ID: %s", searchID)
 
+	acc := NewResultAccumulator(searchID)
+	var lastErr error
+	succeeded := false
+
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		log.Printf("[GetSearchResultsWithPolling] Attempt %d of %d", attempt, maxAttempts)
 
-		result, err := c.GetSearchResults(ctx, searchID)
+		result, err := c.GetSearchResults(ctx, searchID, processors...)
 		if err != nil {
 			log.Printf("[GetSearchResultsWithPolling] Error on attempt %d: %v", attempt, err)
-			// If this is the last attempt, return the error
-			if attempt == maxAttempts {
-				return nil, err
-			}
-			// Otherwise, continue to the next attempt after waiting
-		} else if len(result.Proposals) > 0 {
-			// We found proposals, return the result
-			log.Printf("[GetSearchResultsWithPolling] Found %d proposals on attempt %d", len(result.Proposals), attempt)
-			return result, nil
+			lastErr = err
 		} else {
-			log.Printf("[GetSearchResultsWithPolling] No proposals found on attempt %d, continuing polling", attempt)
+			succeeded = true
+			added := acc.Add(result)
+			log.Printf("[GetSearchResultsWithPolling] Attempt %d added %d proposals (%d total)", attempt, added, acc.Len())
+			if acc.Complete() {
+				log.Printf("[GetSearchResultsWithPolling] Search %s complete after %d attempts", searchID, attempt)
+				return acc.Result(), nil
+			}
+		}
+
+		if attempt == maxAttempts {
+			break
 		}
 
 		// Wait before the next attempt, but check if context is done first
@@ -281,9 +275,12 @@ func (c *Client) GetSearchResultsWithPolling(ctx context.Context, searchID strin
 		}
 	}
 
-	// If we get here, we've exhausted all attempts without finding proposals
-	log.Printf("[GetSearchResultsWithPolling] No proposals found after %d attempts", maxAttempts)
+	// Every attempt failed, nothing to return
+	if !succeeded {
+		return nil, lastErr
+	}
 
-	// Get the final result to return, even if it has no proposals
-	return c.GetSearchResults(ctx, searchID)
+	// Attempts exhausted before completion; return what has been collected so far
+	log.Printf("[GetSearchResultsWithPolling] Search %s incomplete after %d attempts, returning %d proposals", searchID, maxAttempts, acc.Len())
+	return acc.Result(), nil
 }
@@ -0,0 +1,132 @@
+package aviasales_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"stopover.backend/config"
+	"stopover.backend/pkg/aviasales"
+	"stopover.backend/pkg/aviasales/fake"
+)
+
+// exchange is one request the fake server answered
+type exchange struct {
+	method, path string
+	status       int
+	encoding     string
+}
+
+// recorder records the exchanges of the handler it wraps
+type recorder struct {
+	next http.Handler
+
+	mu        sync.Mutex
+	exchanges []exchange
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	r.next.ServeHTTP(sw, req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange{req.Method, req.URL.Path, sw.status, w.Header().Get("Content-Encoding")})
+}
+
+func (r *recorder) to(path string) []exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []exchange
+	for _, e := range r.exchanges {
+		if e.path == path {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func TestClientSearchesFakeServer(t *testing.T) {
+	const polls = 3
+	rec := &recorder{next: fake.New(fake.Options{Token: "tok", Polls: polls, ChunksPerPoll: 2, Gzip: true})}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	cfg := &config.Config{AviaSalesConfig: fake.Config(srv.URL)}
+	client := aviasales.NewFlightIntegrationClient("tok", "marker", "stopover.test", cfg)
+	ctx := context.Background()
+
+	init, err := client.InitSearch(ctx, aviasales.FlightSearchRequest{
+		UserIP:     "203.0.113.7",
+		Locale:     "en",
+		TripClass:  "Y",
+		Passengers: aviasales.PassengerInfo{Adults: 1},
+		Segments:   []aviasales.Segment{{Origin: "DEL", Destination: "COK", Date: "2025-10-01"}},
+	})
+	if err != nil {
+		t.Fatalf("InitSearch: %v", err)
+	}
+	if init.SearchID == "" {
+		t.Fatal("InitSearch returned no search id")
+	}
+
+	// more attempts than polls: the completion marker has to stop the polling
+	result, err := client.GetSearchResultsWithPolling(ctx, init.SearchID, polls+5, time.Millisecond)
+	if err != nil {
+		t.Fatalf("GetSearchResultsWithPolling: %v", err)
+	}
+	if !result.Complete {
+		t.Error("result is not complete")
+	}
+	if want := len(fake.DefaultFixture().Offers); len(result.Proposals) != want {
+		t.Errorf("proposals = %d, want %d", len(result.Proposals), want)
+	}
+	for _, p := range result.Proposals {
+		if len(p.Segment) != 1 || p.Segment[0].Flight[0].Departure != "DEL" {
+			t.Fatalf("proposal %s does not fly the requested segment", p.Sign)
+		}
+	}
+
+	if inits := rec.to(fake.InitPath); len(inits) != 1 || inits[0].method != http.MethodPost || inits[0].status != http.StatusOK {
+		t.Errorf("init requests = %+v, want one accepted POST", inits)
+	}
+	results := rec.to(fake.ResultsPath)
+	if len(results) != polls {
+		t.Errorf("result polls = %d, want %d", len(results), polls)
+	}
+	for _, e := range results {
+		if e.encoding != "gzip" {
+			t.Errorf("result poll answered with encoding %q, want gzip", e.encoding)
+		}
+	}
+}
+
+func TestClientRejectedSignature(t *testing.T) {
+	srv := httptest.NewServer(fake.New(fake.Options{Token: "tok"}))
+	defer srv.Close()
+
+	cfg := &config.Config{AviaSalesConfig: fake.Config(srv.URL)}
+	client := aviasales.NewFlightIntegrationClient("wrong", "marker", "stopover.test", cfg)
+
+	_, err := client.InitSearch(context.Background(), aviasales.FlightSearchRequest{
+		Passengers: aviasales.PassengerInfo{Adults: 1},
+		Segments:   []aviasales.Segment{{Origin: "DEL", Destination: "COK", Date: "2025-10-01"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("error = %v, want the init rejected with 401", err)
+	}
+}
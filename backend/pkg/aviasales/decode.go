@@ -0,0 +1,136 @@
+package aviasales
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxBodyBytes caps a decompressed results response when the config does not set a limit
+const DefaultMaxBodyBytes = 32 << 20
+
+// ErrBodyTooLarge is returned when a response grows past the configured limit
+var ErrBodyTooLarge = errors.New("aviasales: response body too large")
+
+// decodeContent undoes a Content-Encoding. Deflate is accepted both zlib wrapped, as the
+// standard says, and raw, as some servers send it.
+func decodeContent(encoding string, body io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return io.NopCloser(body), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		br := bufio.NewReader(body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	}
+	return nil, fmt.Errorf("aviasales: unsupported content encoding %q", encoding)
+}
+
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+// limitReader fails with ErrBodyTooLarge instead of silently truncating like io.LimitReader
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func newLimitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	return &limitReader{r: r, remaining: limit}
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// only data past the limit is too large; EOF and empty reads pass through
+		var one [1]byte
+		n, err := l.r.Read(one[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// streamResultChunks decodes a results array one element at a time and merges each element
+// as it arrives, so only the accumulated proposals are held in memory rather than the whole
+// body. The API spreads proposals over many chunks and finishes a search with an element that
+// carries nothing but search_id; seeing that element marks the result as complete.
+func streamResultChunks(r io.Reader) (*FlightSearchResponseWrapper, error) {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("read results: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("read results: expected array, got %v", tok)
+	}
+
+	acc := NewResultAccumulator("")
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("read results: %w", err)
+		}
+		chunk, err := decodeChunk(raw)
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("read results: %w", err)
+	}
+	return acc.Result(), nil
+}
+
+func decodeChunk(raw json.RawMessage) (*FlightSearchResponseWrapper, error) {
+	var chunk FlightSearchResponseWrapper
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil, err
+	}
+
+	chunk.Complete = isCompletionMarker(raw)
+	return &chunk, nil
+}
+
+// isCompletionMarker reports whether a results element is an object whose only key is search_id.
+// Only the keys are looked at, so padding or formatting of the marker does not matter, and any
+// other element is rejected at its first key without scanning the rest.
+func isCompletionMarker(raw json.RawMessage) bool {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	if key, err := dec.Token(); err != nil || key != "search_id" {
+		return false
+	}
+	var searchID json.RawMessage
+	if err := dec.Decode(&searchID); err != nil {
+		return false
+	}
+	tok, err := dec.Token()
+	return err == nil && tok == json.Delim('}')
+}
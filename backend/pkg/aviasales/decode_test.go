@@ -0,0 +1,101 @@
+package aviasales
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stutterReader returns (0, nil) before every byte, which io.Reader allows
+type stutterReader struct {
+	data  []byte
+	empty bool
+}
+
+func (s *stutterReader) Read(p []byte) (int, error) {
+	if s.empty = !s.empty; s.empty {
+		return 0, nil
+	}
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p[:1], s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       io.Reader
+		limit   int64
+		want    string
+		wantErr error
+	}{
+		{name: "under limit", r: strings.NewReader("abc"), limit: 5, want: "abc"},
+		{name: "exactly at limit", r: strings.NewReader("abcde"), limit: 5, want: "abcde"},
+		{name: "over limit", r: strings.NewReader("abcdef"), limit: 5, wantErr: ErrBodyTooLarge},
+		{name: "empty reads at limit", r: &stutterReader{data: []byte("abcde")}, limit: 5, want: "abcde"},
+		{name: "empty reads over limit", r: &stutterReader{data: []byte("abcdef")}, limit: 5, wantErr: ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newLimitReader(tt.r, tt.limit))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && string(got) != tt.want {
+				t.Fatalf("read %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadResultsErrorBodyIsDecoded(t *testing.T) {
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	zw.Write([]byte(`{"error":"search expired"}`))
+	zw.Close()
+
+	resp := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:       io.NopCloser(&body),
+	}
+	_, err := (&Client{}).readResults(resp)
+	if err == nil || !strings.Contains(err.Error(), `HTTP 404: {"error":"search expired"}`) {
+		t.Fatalf("error = %v, want the decoded error body", err)
+	}
+}
+
+func TestDecodeChunkCompletionMarker(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{name: "compact", raw: `{"search_id":"abc"}`, want: true},
+		{name: "whitespace formatted", raw: "{\n  \"search_id\" : \"abc\"\n}\n", want: true},
+		{name: "padded past 256 bytes", raw: "{" + strings.Repeat(" ", 512) + `"search_id":"abc"` + strings.Repeat("\n", 512) + "}", want: true},
+		{name: "with proposals", raw: `{"search_id":"abc","proposals":[]}`},
+		{name: "search_id last", raw: `{"proposals":[],"search_id":"abc"}`},
+		{name: "empty object", raw: `{}`},
+		{name: "other single key", raw: `{"meta":{}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk, err := decodeChunk(json.RawMessage(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chunk.Complete != tt.want {
+				t.Fatalf("Complete = %t, want %t", chunk.Complete, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package fake
+
+import (
+	"crypto/md5"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"stopover.backend/pkg/aviasales"
+)
+
+//go:embed fixtures/offers.json
+var fixtureFS embed.FS
+
+// Fixture describes the offers the fake server answers every search with. Offers are route
+// templates: they are flown between the requested origin and destination on the requested date,
+// optionally through the via airports. A requested city code is flown from the city's airports
+// in turn, so city searches return flights from several of them.
+type Fixture struct {
+	Currency string                       `json:"currency"`
+	Airlines map[string]string            `json:"airlines"`
+	Airports map[string]aviasales.Airport `json:"airports"`
+	Cities   map[string][]string          `json:"cities"`
+	Offers   []Offer                      `json:"offers"`
+}
+
+type Offer struct {
+	Via   []string                      `json:"via"`
+	Hops  []Hop                         `json:"hops"`
+	Fares map[string]aviasales.TermData `json:"fares"`
+}
+
+// Hop is one flight of an offer. Depart applies to the first hop only; later hops leave
+// Layover minutes after the previous one lands, from the airport it landed at unless From
+// names another one the traveller has to transfer to.
+type Hop struct {
+	Carrier  string `json:"carrier"`
+	Number   string `json:"number"`
+	Aircraft string `json:"aircraft"`
+	Depart   string `json:"depart"`
+	From     string `json:"from"`
+	Layover  int    `json:"layover"`
+	Duration int    `json:"duration"`
+}
+
+// DefaultFixture returns the embedded fixture
+func DefaultFixture() *Fixture {
+	f, err := fixtureFS.Open("fixtures/offers.json")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	fixture, err := LoadFixture(f)
+	if err != nil {
+		panic(fmt.Errorf("embedded fixture: %w", err))
+	}
+	return fixture
+}
+
+// LoadFixture reads a fixture in the format of fixtures/offers.json
+func LoadFixture(r io.Reader) (*Fixture, error) {
+	var f Fixture
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	for i, o := range f.Offers {
+		if len(o.Hops) == 0 || len(o.Via) != len(o.Hops)-1 {
+			return nil, fmt.Errorf("offer %d: need one via airport between every pair of hops", i)
+		}
+		if _, err := time.Parse("15:04", o.Hops[0].Depart); err != nil {
+			return nil, fmt.Errorf("offer %d: invalid depart %q", i, o.Hops[0].Depart)
+		}
+	}
+	return &f, nil
+}
+
+// proposals builds the proposals answering a search request
+func (f *Fixture) proposals(req aviasales.FlightSearchRequest) []aviasales.Proposal {
+	out := make([]aviasales.Proposal, 0, len(f.Offers))
+	url := 1000
+	for i, o := range f.Offers {
+		direct := len(o.Hops) == 1
+		p := aviasales.Proposal{
+			Terms:    make(map[string]aviasales.TermData, len(o.Fares)),
+			IsDirect: &direct,
+		}
+
+		seen := make(map[string]bool)
+		for _, s := range req.Segments {
+			seg := o.fly(f.airport(s.Origin, i), f.airport(s.Destination, i), s.Date, req.TripClass)
+			for _, fl := range seg.Flight {
+				p.TotalDuration += fl.Duration
+				if !seen[fl.MarketingCarrier] {
+					seen[fl.MarketingCarrier] = true
+					p.Carriers = append(p.Carriers, fl.MarketingCarrier)
+				}
+			}
+			p.Segment = append(p.Segment, seg)
+		}
+
+		// fares scale with passengers and segments like a real supplier would roughly do
+		factor := float64(req.Passengers.Adults+req.Passengers.Children) + 0.1*float64(req.Passengers.Infants)
+		factor *= float64(len(req.Segments))
+		for gate, term := range o.Fares {
+			url++
+			term.Price *= factor
+			term.UnifiedPrice *= factor
+			term.URL = aviasales.FlexibleURL{Value: float64(url)}
+			p.Terms[gate] = term
+		}
+
+		p.Sign = sign(p)
+		out = append(out, p)
+	}
+	return out
+}
+
+// airport resolves a requested code for the offer with the given index
+func (f *Fixture) airport(code string, offer int) string {
+	code = strings.ToUpper(code)
+	if airports := f.Cities[code]; len(airports) > 0 {
+		return airports[offer%len(airports)]
+	}
+	return code
+}
+
+// fly lays the offer's hops over one requested segment
+func (o Offer) fly(origin, destination, date, tripClass string) aviasales.FlightSegment {
+	day, _ := time.Parse("2006-01-02", date)
+	clock, _ := time.Parse("15:04", o.Hops[0].Depart)
+	dep := day.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+
+	seg := aviasales.FlightSegment{}
+	from := origin
+	for i, h := range o.Hops {
+		to := destination
+		if i < len(o.Via) {
+			to = o.Via[i]
+		}
+		if i > 0 {
+			dep = dep.Add(time.Duration(h.Layover) * time.Minute)
+			if h.From != "" {
+				from = h.From
+			}
+		}
+		arr := dep.Add(time.Duration(h.Duration) * time.Minute)
+
+		seg.Flight = append(seg.Flight, aviasales.Flight{
+			Aircraft:         h.Aircraft,
+			Departure:        from,
+			DepartureDate:    dep.Format("2006-01-02"),
+			DepartureTime:    dep.Format("15:04"),
+			Arrival:          to,
+			ArrivalDate:      arr.Format("2006-01-02"),
+			ArrivalTime:      arr.Format("15:04"),
+			Duration:         h.Duration,
+			MarketingCarrier: h.Carrier,
+			OperatingCarrier: h.Carrier,
+			Number:           h.Number,
+			TripClass:        tripClass,
+		})
+		from, dep = to, arr
+	}
+	return seg
+}
+
+// airports returns the metadata for every airport flown in the proposals. Airports missing from
+// the fixture are reported in UTC.
+func (f *Fixture) airports(proposals []aviasales.Proposal) map[string]aviasales.Airport {
+	out := make(map[string]aviasales.Airport)
+	add := func(code string) {
+		if _, ok := out[code]; ok {
+			return
+		}
+		if a, ok := f.Airports[code]; ok {
+			out[code] = a
+			return
+		}
+		out[code] = aviasales.Airport{Name: code, City: code, CityCode: code, TimeZone: "UTC"}
+	}
+	for _, p := range proposals {
+		for _, seg := range p.Segment {
+			for _, fl := range seg.Flight {
+				add(fl.Departure)
+				add(fl.Arrival)
+			}
+		}
+	}
+	return out
+}
+
+func (f *Fixture) airlines() map[string]aviasales.Airline {
+	out := make(map[string]aviasales.Airline, len(f.Airlines))
+	for code, name := range f.Airlines {
+		out[code] = aviasales.Airline{IATA: code, Name: name}
+	}
+	return out
+}
+
+func sign(p aviasales.Proposal) string {
+	b, _ := json.Marshal(p.Segment)
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,287 @@
+// Package fake is a local stand-in for the Travelpayouts flight search API. It speaks the same
+// init, results and click protocol as the real supplier so the whole search flow can run without network.
+package fake
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stopover.backend/config"
+	"stopover.backend/pkg/aviasales"
+)
+
+const (
+	InitPath    = "/v1/flight_search"
+	ResultsPath = "/v1/flight_search_results"
+	ClicksPath  = "/v1/flight_searches/"
+
+	searchRetention = 30 * time.Minute
+)
+
+// Options controls how the fake server behaves; zero values give a fast, well-behaved server
+type Options struct {
+	// Token verifies request signatures when set; leave empty to accept any signature
+	Token string
+	// Fixture supplies the offers; the embedded fixture is used when nil
+	Fixture *Fixture
+	// Polls is the number of result polls a search is spread over before it completes
+	Polls int
+	// ChunksPerPoll is the number of array elements each poll response is split into
+	ChunksPerPoll int
+	// Latency is added to every response
+	Latency time.Duration
+	// ErrorRate is the probability in [0,1] that any request fails with a 500
+	ErrorRate float64
+	// FailPolls makes the first n result polls of every search fail with a 503
+	FailPolls int
+	// Gzip compresses responses for clients that accept it
+	Gzip bool
+}
+
+type search struct {
+	id        string
+	proposals []aviasales.Proposal
+	airports  map[string]aviasales.Airport
+	polls     int
+	delivered int
+	createdAt time.Time
+}
+
+// Server implements the init, results and click endpoints of the flight search API
+type Server struct {
+	opts Options
+
+	mu       sync.Mutex
+	searches map[string]*search
+	rnd      *mrand.Rand
+}
+
+func New(opts Options) *Server {
+	if opts.Fixture == nil {
+		opts.Fixture = DefaultFixture()
+	}
+	if opts.Polls <= 0 {
+		opts.Polls = 3
+	}
+	if opts.ChunksPerPoll <= 0 {
+		opts.ChunksPerPoll = 2
+	}
+	return &Server{
+		opts:     opts,
+		searches: make(map[string]*search),
+		rnd:      mrand.New(mrand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Config returns the client settings pointing at a fake server listening on baseURL
+func Config(baseURL string) config.AviaSalesConfig {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return config.AviaSalesConfig{
+		InitSearchURL:   baseURL + InitPath,
+		ResultSearchURL: baseURL + ResultsPath + "?uuid=%s",
+		BookingURL:      baseURL + ClicksPath + "%s/clicks/%s.json",
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.wait(r.Context()) {
+		return
+	}
+	if s.injectError() {
+		s.writeJSON(w, r, http.StatusInternalServerError, map[string]string{"error": "injected failure"})
+		return
+	}
+
+	switch {
+	case r.URL.Path == InitPath && r.Method == http.MethodPost:
+		s.initSearch(w, r)
+	case r.URL.Path == ResultsPath && r.Method == http.MethodGet:
+		s.results(w, r)
+	case strings.HasPrefix(r.URL.Path, ClicksPath) && r.Method == http.MethodGet:
+		s.click(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) initSearch(w http.ResponseWriter, r *http.Request) {
+	var req aviasales.FlightSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	if len(req.Segments) == 0 || req.Passengers.Adults < 1 {
+		s.writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "segments and adults are required"})
+		return
+	}
+
+	if s.opts.Token != "" {
+		want := aviasales.GenerateSignature(s.opts.Token, req.Marker, req.Host, req.Locale, req.TripClass,
+			req.UserIP, req.Passengers, req.Segments)
+		if req.Signature != want {
+			s.writeJSON(w, r, http.StatusUnauthorized, map[string]string{"error": "invalid signature"})
+			return
+		}
+	}
+
+	id := newID()
+	proposals := s.opts.Fixture.proposals(req)
+	srch := &search{
+		id:        id,
+		proposals: proposals,
+		airports:  s.opts.Fixture.airports(proposals),
+		polls:     s.opts.Polls,
+		createdAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	for k, old := range s.searches {
+		if time.Since(old.createdAt) > searchRetention {
+			delete(s.searches, k)
+		}
+	}
+	s.searches[id] = srch
+	s.mu.Unlock()
+
+	log.Printf("[fake.initSearch] %s %d segments, %d proposals", id, len(req.Segments), len(srch.proposals))
+	s.writeJSON(w, r, http.StatusOK, aviasales.FlightSearchInitResponse{SearchID: id, UUID: id})
+}
+
+// results hands out the next slice of proposals; the poll that hands out the last slice also
+// carries the completion marker, and polls after that return only the marker
+func (s *Server) results(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("uuid")
+
+	s.mu.Lock()
+	srch, ok := s.searches[id]
+	if !ok {
+		s.mu.Unlock()
+		s.writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "unknown search"})
+		return
+	}
+	srch.delivered++
+	poll := srch.delivered
+	s.mu.Unlock()
+
+	if poll <= s.opts.FailPolls {
+		s.writeJSON(w, r, http.StatusServiceUnavailable, map[string]string{"error": "injected poll failure"})
+		return
+	}
+	poll -= s.opts.FailPolls
+
+	chunks := make([]interface{}, 0, s.opts.ChunksPerPoll+1)
+	if poll <= srch.polls {
+		batch := part(srch.proposals, poll-1, srch.polls)
+		for i := 0; i < s.opts.ChunksPerPoll; i++ {
+			chunks = append(chunks, aviasales.FlightSearchResponseWrapper{
+				SearchID:  id,
+				Proposals: part(batch, i, s.opts.ChunksPerPoll),
+				Airports:  srch.airports,
+				Airlines:  s.opts.Fixture.airlines(),
+				Currency:  s.opts.Fixture.Currency,
+			})
+		}
+	}
+	if poll >= srch.polls {
+		chunks = append(chunks, map[string]string{"search_id": id})
+	}
+	s.writeJSON(w, r, http.StatusOK, chunks)
+}
+
+// click resolves /v1/flight_searches/{id}/clicks/{term}.json to a made-up agency url
+func (s *Server) click(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, ClicksPath)
+	id, term, ok := strings.Cut(rest, "/clicks/")
+	term = strings.TrimSuffix(term, ".json")
+	if !ok || term == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	srch, found := s.searches[id]
+	s.mu.Unlock()
+	if !found {
+		s.writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "unknown search"})
+		return
+	}
+
+	for _, p := range srch.proposals {
+		for gate, t := range p.Terms {
+			if t.URL.String() != term {
+				continue
+			}
+			click := time.Now().UnixNano()
+			gateID, _ := strconv.Atoi(gate)
+			s.writeJSON(w, r, http.StatusOK, aviasales.BookingLink{
+				URL:        "https://agency-" + gate + ".example.com/book?offer=" + term + "&marker=" + r.URL.Query().Get("marker"),
+				Method:     http.MethodGet,
+				Params:     map[string]string{},
+				GateID:     gateID,
+				ClickID:    click,
+				StrClickID: strconv.FormatInt(click, 10),
+				ExpireAt:   time.Now().Add(15 * time.Minute).Unix(),
+			})
+			return
+		}
+	}
+	s.writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "unknown term"})
+}
+
+// part returns the i-th of n nearly equal slices of ps
+func part(ps []aviasales.Proposal, i, n int) []aviasales.Proposal {
+	return ps[i*len(ps)/n : (i+1)*len(ps)/n]
+}
+
+func (s *Server) wait(ctx context.Context) bool {
+	if s.opts.Latency <= 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(s.opts.Latency):
+		return true
+	}
+}
+
+func (s *Server) injectError() bool {
+	if s.opts.ErrorRate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Float64() < s.opts.ErrorRate
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !s.opts.Gzip || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+	_ = json.NewEncoder(gz).Encode(v)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -41,6 +41,19 @@ type FlightSearchResponseWrapper struct {
 	Airports  map[string]Airport `json:"airports,omitempty"`
 	Airlines  map[string]Airline `json:"airlines,omitempty"`
 	Currency  string             `json:"currency,omitempty"`
+	Complete  bool               `json:"complete"`
+}
+
+// BookingLink is the agency redirect for one term of a search. Most agencies take a GET to URL;
+// some need Params posted to it with Method.
+type BookingLink struct {
+	URL        string            `json:"url"`
+	Method     string            `json:"method"`
+	Params     map[string]string `json:"params"`
+	GateID     int               `json:"gate_id"`
+	ClickID    int64             `json:"click_id"`
+	StrClickID string            `json:"str_click_id"`
+	ExpireAt   int64             `json:"expire_at"`
 }
 
 // Core flight data structures
@@ -49,10 +62,24 @@ type Proposal struct {
 	Segment       []FlightSegment     `json:"segment"`
 	TotalDuration int                 `json:"total_duration"`
 	Carriers      []string            `json:"carriers"`
-	IsDirect      bool                `json:"is_direct"`
+	IsDirect      *bool               `json:"is_direct,omitempty"` // nil when the supplier sent none
 	Sign          string              `json:"sign"`
 }
 
+// Direct reports the supplier's direct flag, or whether every segment is a single flight when
+// the flag was not sent
+func (p Proposal) Direct() bool {
+	if p.IsDirect != nil {
+		return *p.IsDirect
+	}
+	for _, seg := range p.Segment {
+		if len(seg.Flight) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
 // FlexibleURL handles both string and number types for URL field
 type FlexibleURL struct {
 	Value interface{}
@@ -84,6 +111,11 @@ func (f *FlexibleURL) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("cannot unmarshal %s into FlexibleURL", data)
 }
 
+// MarshalJSON writes the URL back in the form it was received
+func (f FlexibleURL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Value)
+}
+
 // String returns string representation of the URL
 func (f *FlexibleURL) String() string {
 	if f.Value == nil {
@@ -0,0 +1,182 @@
+package aviasales
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// Processor transforms a fetched results response in place. Processors run in the order they
+// are given to GetSearchResults; a failing processor stops the chain and fails the fetch.
+type Processor interface {
+	Process(ctx context.Context, res *FlightSearchResponseWrapper) error
+}
+
+// ProcessorFunc adapts a plain function to Processor
+type ProcessorFunc func(ctx context.Context, res *FlightSearchResponseWrapper) error
+
+func (f ProcessorFunc) Process(ctx context.Context, res *FlightSearchResponseWrapper) error {
+	return f(ctx, res)
+}
+
+// DefaultPipeline is what search flows opt into; raw debugging fetches pass no processors
+var DefaultPipeline = []Processor{
+	NormalizeCurrency(),
+	ValidateProposals(),
+	DedupeProposals(),
+	EnrichProposals(),
+}
+
+func runPipeline(ctx context.Context, res *FlightSearchResponseWrapper, processors []Processor) error {
+	for _, p := range processors {
+		if err := p.Process(ctx, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeCurrency lowercases currency codes, fills in a term's missing currency from the
+// response, and derives a missing unified price when the term is already in the unified currency.
+// Terms without a positive price are dropped.
+func NormalizeCurrency() Processor {
+	return ProcessorFunc(func(ctx context.Context, res *FlightSearchResponseWrapper) error {
+		res.Currency = strings.ToLower(strings.TrimSpace(res.Currency))
+		for i := range res.Proposals {
+			p := &res.Proposals[i]
+			for gate, term := range p.Terms {
+				if term.Price <= 0 {
+					delete(p.Terms, gate)
+					continue
+				}
+				term.Currency = strings.ToLower(strings.TrimSpace(term.Currency))
+				if term.Currency == "" {
+					term.Currency = res.Currency
+				}
+				if term.UnifiedPrice <= 0 && term.Currency == res.Currency {
+					term.UnifiedPrice = term.Price
+				}
+				p.Terms[gate] = term
+			}
+		}
+		return nil
+	})
+}
+
+// ValidateProposals drops proposals that cannot be shown or booked: no fares, no segments, or
+// flights without airports, dates or times
+func ValidateProposals() Processor {
+	return ProcessorFunc(func(ctx context.Context, res *FlightSearchResponseWrapper) error {
+		kept := res.Proposals[:0]
+		for _, p := range res.Proposals {
+			if reason := invalidProposal(p); reason != "" {
+				log.Printf("[ValidateProposals] dropping proposal %q: %s", p.Sign, reason)
+				continue
+			}
+			kept = append(kept, p)
+		}
+		res.Proposals = kept
+		return nil
+	})
+}
+
+func invalidProposal(p Proposal) string {
+	if len(p.Terms) == 0 {
+		return "no fares"
+	}
+	if len(p.Segment) == 0 {
+		return "no segments"
+	}
+	for _, seg := range p.Segment {
+		if len(seg.Flight) == 0 {
+			return "empty segment"
+		}
+		for _, f := range seg.Flight {
+			if f.Departure == "" || f.Arrival == "" {
+				return "flight without airports"
+			}
+			if f.DepartureDate == "" || f.DepartureTime == "" || f.ArrivalDate == "" || f.ArrivalTime == "" {
+				return "flight without schedule"
+			}
+		}
+	}
+	return ""
+}
+
+// DedupeProposals collapses proposals describing the same flights, merging their fares. Proposals
+// are matched by sign, or by their flights when the supplier sent no sign.
+func DedupeProposals() Processor {
+	return ProcessorFunc(func(ctx context.Context, res *FlightSearchResponseWrapper) error {
+		index := make(map[string]int, len(res.Proposals))
+		kept := res.Proposals[:0]
+		for _, p := range res.Proposals {
+			key := p.Sign
+			if key == "" {
+				key = flightsKey(p)
+			}
+			if i, ok := index[key]; ok {
+				mergeTerms(&kept[i], p.Terms)
+				continue
+			}
+			index[key] = len(kept)
+			kept = append(kept, p)
+		}
+		res.Proposals = kept
+		return nil
+	})
+}
+
+func flightsKey(p Proposal) string {
+	var b strings.Builder
+	for _, seg := range p.Segment {
+		for _, f := range seg.Flight {
+			b.WriteString(f.MarketingCarrier + f.Number + "@" + f.DepartureDate + "T" + f.DepartureTime + ",")
+		}
+		b.WriteString("/")
+	}
+	return b.String()
+}
+
+// EnrichProposals fills in fields the supplier sometimes leaves empty: carriers, the direct flag,
+// the total duration and an airline entry for every carrier that is flown. Values the supplier
+// sent are kept, so a one-flight proposal it marks as not direct, such as one with a technical
+// stop, stays that way.
+func EnrichProposals() Processor {
+	return ProcessorFunc(func(ctx context.Context, res *FlightSearchResponseWrapper) error {
+		if res.Airlines == nil {
+			res.Airlines = make(map[string]Airline)
+		}
+		for i := range res.Proposals {
+			p := &res.Proposals[i]
+
+			seen := make(map[string]bool, len(p.Carriers))
+			for _, c := range p.Carriers {
+				seen[c] = true
+			}
+			duration := 0
+			for _, seg := range p.Segment {
+				for _, f := range seg.Flight {
+					duration += f.Duration
+					if f.MarketingCarrier != "" && !seen[f.MarketingCarrier] {
+						seen[f.MarketingCarrier] = true
+						p.Carriers = append(p.Carriers, f.MarketingCarrier)
+					}
+				}
+			}
+			if p.IsDirect == nil {
+				direct := p.Direct()
+				p.IsDirect = &direct
+			}
+			if p.TotalDuration == 0 {
+				p.TotalDuration = duration
+			}
+
+			for _, c := range p.Carriers {
+				if _, ok := res.Airlines[c]; !ok {
+					res.Airlines[c] = Airline{IATA: c, Name: c}
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,43 @@
+package aviasales
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestEnrichProposalsKeepsSuppliedDirectFlag(t *testing.T) {
+	oneFlight := `"segment":[{"flight":[{"departure":"DEL","arrival":"COK","duration":190}]}]`
+	twoFlights := `"segment":[{"flight":[{"departure":"DEL","arrival":"BOM","duration":120},{"departure":"BOM","arrival":"COK","duration":110}]}]`
+
+	tests := []struct {
+		name     string
+		proposal string
+		want     bool
+	}{
+		{name: "absent on one flight", proposal: `{` + oneFlight + `}`, want: true},
+		{name: "absent on two flights", proposal: `{` + twoFlights + `}`, want: false},
+		{name: "technical stop marked not direct", proposal: `{"is_direct":false,` + oneFlight + `}`, want: false},
+		{name: "supplied true", proposal: `{"is_direct":true,` + oneFlight + `}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Proposal
+			if err := json.Unmarshal([]byte(tt.proposal), &p); err != nil {
+				t.Fatal(err)
+			}
+			res := &FlightSearchResponseWrapper{Proposals: []Proposal{p}}
+			if err := EnrichProposals().Process(context.Background(), res); err != nil {
+				t.Fatal(err)
+			}
+
+			got := res.Proposals[0]
+			if got.IsDirect == nil {
+				t.Fatal("direct flag was not filled in")
+			}
+			if *got.IsDirect != tt.want || got.Direct() != tt.want {
+				t.Errorf("is_direct = %t, want %t", *got.IsDirect, tt.want)
+			}
+		})
+	}
+}
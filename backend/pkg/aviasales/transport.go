@@ -0,0 +1,231 @@
+package aviasales
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TransportConfig tunes retries, timeouts and the circuit breaker of the supplier transport
+type TransportConfig struct {
+	MaxRetries       int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	AttemptTimeout   time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// DefaultTransportConfig is used for every zero field of a TransportConfig
+var DefaultTransportConfig = TransportConfig{
+	MaxRetries:       3,
+	BaseDelay:        200 * time.Millisecond,
+	MaxDelay:         5 * time.Second,
+	AttemptTimeout:   15 * time.Second,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+func (tc TransportConfig) withDefaults() TransportConfig {
+	d := DefaultTransportConfig
+	if tc.MaxRetries == 0 {
+		tc.MaxRetries = d.MaxRetries
+	}
+	if tc.BaseDelay <= 0 {
+		tc.BaseDelay = d.BaseDelay
+	}
+	if tc.MaxDelay <= 0 {
+		tc.MaxDelay = d.MaxDelay
+	}
+	if tc.AttemptTimeout <= 0 {
+		tc.AttemptTimeout = d.AttemptTimeout
+	}
+	if tc.BreakerThreshold == 0 {
+		tc.BreakerThreshold = d.BreakerThreshold
+	}
+	if tc.BreakerCooldown <= 0 {
+		tc.BreakerCooldown = d.BreakerCooldown
+	}
+	return tc
+}
+
+// ResilientTransport retries failed supplier calls and stops calling a supplier that keeps failing.
+//
+// Network errors and 5xx responses are retried only for idempotent methods, so a search is never
+// started twice. 429 responses are retried for every method since the request was not processed,
+// waiting for Retry-After when the supplier sends it. Each attempt gets its own deadline, bounded
+// by the deadline of the request context. A negative MaxRetries or BreakerThreshold disables the
+// corresponding feature.
+type ResilientTransport struct {
+	base    http.RoundTripper
+	cfg     TransportConfig
+	breaker *CircuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func NewResilientTransport(base http.RoundTripper, cfg TransportConfig) *ResilientTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cfg = cfg.withDefaults()
+	return &ResilientTransport{
+		base:    base,
+		cfg:     cfg,
+		breaker: NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		sleep:   sleepContext,
+	}
+}
+
+func (t *ResilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	for attempt := 0; ; attempt++ {
+		if err := t.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.attempt(attemptReq)
+		retryable := false
+		var wait time.Duration
+
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				t.breaker.Abort()
+				return nil, ctx.Err()
+			}
+			t.breaker.Failure()
+			retryable = idempotent
+		case resp.StatusCode == http.StatusTooManyRequests:
+			// a throttled response still proves the supplier is up
+			t.breaker.Success()
+			retryable = true
+			// a long Retry-After would hold the caller for minutes; wait no longer than any backoff
+			wait = min(retryAfter(resp.Header.Get("Retry-After")), t.cfg.MaxDelay)
+		case resp.StatusCode >= http.StatusInternalServerError:
+			t.breaker.Failure()
+			retryable = idempotent
+		default:
+			t.breaker.Success()
+			return resp, nil
+		}
+
+		if !retryable || attempt >= t.cfg.MaxRetries {
+			return resp, err
+		}
+
+		if wait <= 0 {
+			wait = t.backoff(attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// the caller gives up before the next attempt could run
+			return resp, err
+		}
+
+		if resp != nil {
+			drain(resp)
+		}
+		log.Printf("[ResilientTransport] %s %s attempt %d failed (%v), retrying in %s", req.Method, req.URL.Path, attempt+1, failure(resp, err), wait)
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends one request under its own deadline; the deadline is released when the body is closed
+func (t *ResilientTransport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.cfg.AttemptTimeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff is exponential with full jitter, capped at MaxDelay
+func (t *ResilientTransport) backoff(attempt int) time.Duration {
+	d := t.cfg.BaseDelay << attempt
+	if d <= 0 || d > t.cfg.MaxDelay {
+		d = t.cfg.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+// rewind returns a request whose body can be sent again
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("aviasales: request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// retryAfter reads a Retry-After header given in seconds or as an HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func failure(resp *http.Response, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return resp.Status
+}
+
+// drain discards the rest of a response so its connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
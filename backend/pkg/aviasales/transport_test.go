@@ -0,0 +1,144 @@
+package aviasales
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// reply scripts one response of a test server
+type reply func(w http.ResponseWriter, r *http.Request)
+
+func status(code int) reply {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func throttled(retryAfter string) reply {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+// dropConnection closes the connection without a response, which the client sees as a network error
+func dropConnection(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// scriptedServer answers the nth request with the nth reply and repeats the last one after that
+func scriptedServer(t *testing.T, replies ...reply) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		replies[min(n, len(replies)-1)](w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// newTestTransport records retry waits instead of sleeping
+func newTestTransport(cfg TransportConfig) (*ResilientTransport, *[]time.Duration) {
+	var waits []time.Duration
+	tr := NewResilientTransport(nil, cfg)
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return tr, &waits
+}
+
+func TestResilientTransportRetries(t *testing.T) {
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name       string
+		method     string
+		replies    []reply
+		wantCalls  int32
+		wantStatus int
+		wantErr    bool
+		maxDelay   time.Duration
+		// the first retry wait is checked to be within a second below wantWait
+		wantWait time.Duration
+	}{
+		{name: "GET retries 5xx", method: http.MethodGet, replies: []reply{status(502), status(503), status(200)}, wantCalls: 3, wantStatus: 200},
+		{name: "GET gives up after MaxRetries", method: http.MethodGet, replies: []reply{status(500)}, wantCalls: 4, wantStatus: 500},
+		{name: "POST does not retry 5xx", method: http.MethodPost, replies: []reply{status(503), status(200)}, wantCalls: 1, wantStatus: 503},
+		{name: "GET retries network errors", method: http.MethodGet, replies: []reply{dropConnection, status(200)}, wantCalls: 2, wantStatus: 200},
+		{name: "POST does not retry network errors", method: http.MethodPost, replies: []reply{dropConnection, status(200)}, wantCalls: 1, wantErr: true},
+		{name: "GET does not retry 4xx", method: http.MethodGet, replies: []reply{status(404), status(200)}, wantCalls: 1, wantStatus: 404},
+		{name: "429 Retry-After seconds", method: http.MethodGet, replies: []reply{throttled("7"), status(200)}, wantCalls: 2, wantStatus: 200, maxDelay: time.Minute, wantWait: 7 * time.Second},
+		{name: "429 Retry-After HTTP date", method: http.MethodGet, replies: []reply{throttled(date), status(200)}, wantCalls: 2, wantStatus: 200, maxDelay: time.Minute, wantWait: 30 * time.Second},
+		{name: "429 Retry-After capped at MaxDelay", method: http.MethodGet, replies: []reply{throttled("3600"), status(200)}, wantCalls: 2, wantStatus: 200, wantWait: 5 * time.Second},
+		{name: "429 Retry-After HTTP date capped at MaxDelay", method: http.MethodGet, replies: []reply{throttled(date), status(200)}, wantCalls: 2, wantStatus: 200, maxDelay: 10 * time.Second, wantWait: 10 * time.Second},
+		{name: "POST retries 429", method: http.MethodPost, replies: []reply{throttled("1"), status(200)}, wantCalls: 2, wantStatus: 200, wantWait: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := scriptedServer(t, tt.replies...)
+			tr, waits := newTestTransport(TransportConfig{MaxRetries: 3, MaxDelay: tt.maxDelay, BreakerThreshold: -1})
+
+			req, _ := http.NewRequest(tt.method, srv.URL, strings.NewReader(`{"q":1}`))
+			resp, err := tr.RoundTrip(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantWait > 0 {
+				if len(*waits) == 0 {
+					t.Fatal("no retry wait recorded")
+				}
+				if w := (*waits)[0]; w > tt.wantWait || w < tt.wantWait-time.Second {
+					t.Errorf("wait = %s, want about %s", w, tt.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestResilientTransportAttemptTimeoutCancelsSlowBody(t *testing.T) {
+	srv, _ := scriptedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"search_id":`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	tr, _ := newTestTransport(TransportConfig{AttemptTimeout: 100 * time.Millisecond, BreakerThreshold: -1})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("read error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("body read took %s, want it cut off by the attempt deadline", elapsed)
+	}
+}
@@ -24,3 +24,19 @@ func ValidateRequest(ctx *gin.Context, req interface{}) error {
 	}
 	return nil
 }
+
+func ValidateQuery(ctx *gin.Context, req interface{}) error {
+
+	if err := ctx.ShouldBindQuery(req); err != nil {
+
+		return errors.New("Invalid input")
+	}
+	validate := validator.New()
+
+	err := validate.Struct(req)
+	if err != nil {
+		errs := err.(validator.ValidationErrors)
+		return errors.New(fmt.Sprintf("Validation error: %s", errs))
+	}
+	return nil
+}
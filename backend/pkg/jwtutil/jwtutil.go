@@ -73,12 +73,18 @@ func (c *tokenSvc) ValidateRefreshToken(token string) (*models.User, error) {
 
 func (c *tokenSvc) validateToken(token string) (*models.User, error) {
 
-	parsedToken, _ := jwt.ParseWithClaims(token, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
+	// without a secret anyone could sign tokens, so nothing is accepted
+	if c.cfg.SecretKey == "" {
+		slog.Error("token validation disabled, SECRET_KEY is not set")
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	parsedToken, err := jwt.ParseWithClaims(token, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(c.cfg.SecretKey), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
-	// Check if the token is valid
-	if !parsedToken.Valid {
+	// Check if the token is valid; a malformed token yields no token at all
+	if err != nil || parsedToken == nil || !parsedToken.Valid {
 		slog.Error("invalid token")
 		return nil, fmt.Errorf("invalid token")
 	}
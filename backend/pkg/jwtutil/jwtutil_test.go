@@ -0,0 +1,59 @@
+package jwtutil
+
+import (
+	"testing"
+	"time"
+
+	"stopover.backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signed(t *testing.T, method jwt.SigningMethod, key interface{}, expires time.Time) string {
+	t.Helper()
+	claims := UserClaims{
+		Id:     7,
+		RoleId: 2,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	secret := []byte("s3cret")
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid HS256", secret: "s3cret", token: signed(t, jwt.SigningMethodHS256, secret, later)},
+		{name: "empty secret accepts nothing", secret: "", token: signed(t, jwt.SigningMethodHS256, []byte(""), later), wantErr: true},
+		{name: "other HMAC algorithm", secret: "s3cret", token: signed(t, jwt.SigningMethodHS512, secret, later), wantErr: true},
+		{name: "unsigned", secret: "s3cret", token: signed(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, later), wantErr: true},
+		{name: "malformed", secret: "s3cret", token: "not-a-jwt", wantErr: true},
+		{name: "empty", secret: "s3cret", token: "", wantErr: true},
+		{name: "wrong secret", secret: "s3cret", token: signed(t, jwt.SigningMethodHS256, []byte("other"), later), wantErr: true},
+		{name: "expired", secret: "s3cret", token: signed(t, jwt.SigningMethodHS256, secret, time.Now().Add(-time.Minute)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewTokenService(config.Config{SecretKey: tt.secret}).ValidateAccessToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && (user.UserId != 7 || user.RoleId != 2) {
+				t.Fatalf("user = %+v, want id 7 role 2", user)
+			}
+		})
+	}
+}
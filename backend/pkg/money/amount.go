@@ -0,0 +1,144 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places an Amount keeps
+const Scale = 2
+
+const unit = 100
+
+// Amount is an exact decimal amount of money stored in hundredths of the currency unit.
+// It avoids float64 rounding drift when prices are compared, summed or converted.
+type Amount int64
+
+// FromFloat converts a float price from a supplier payload, rounding to the nearest hundredth
+func FromFloat(v float64) Amount {
+	return Amount(math.Round(v * unit))
+}
+
+// FromUnits builds an amount from whole units and hundredths, e.g. FromUnits(12, 50) is 12.50
+func FromUnits(units, cents int64) Amount {
+	return Amount(units*unit + cents)
+}
+
+// FromRat rounds an exact rational to the nearest hundredth, halves away from zero.
+// It returns an error when the result does not fit in an Amount.
+func FromRat(r *big.Rat) (Amount, error) {
+	scaled := new(big.Rat).Mul(r, big.NewRat(unit, 1))
+	q, m := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// round half away from zero: compare twice the remainder with the denominator
+	m.Abs(m).Lsh(m, 1)
+	if m.Cmp(scaled.Denom()) >= 0 {
+		if scaled.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("money: %s overflows an amount", r.FloatString(Scale))
+	}
+	return Amount(q.Int64()), nil
+}
+
+// Rat returns the exact value of the amount
+func (a Amount) Rat() *big.Rat {
+	return big.NewRat(int64(a), unit)
+}
+
+// Parse reads a decimal string such as "1234.5" or "-0.07"
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("money: empty amount")
+	}
+
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if len(frac) > Scale {
+		return 0, fmt.Errorf("money: %q has more than %d decimal places", s, Scale)
+	}
+	frac += strings.Repeat("0", Scale-len(frac))
+
+	// ParseInt accepts a sign, so the parts are checked for plain digits first
+	if !digits(whole) || !digits(frac) {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	if w > (math.MaxInt64-f)/unit {
+		return 0, fmt.Errorf("money: %q overflows an amount", s)
+	}
+
+	a := Amount(w*unit + f)
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Float64 returns the amount as a float for display-only purposes
+func (a Amount) Float64() float64 {
+	return float64(a) / unit
+}
+
+// String formats the amount with exactly two decimal places
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/unit, v%unit)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and quoted decimal strings
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
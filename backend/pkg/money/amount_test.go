@@ -0,0 +1,38 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "1234.5", want: 123450},
+		{in: "-0.07", want: -7},
+		{in: "+3", want: 300},
+		{in: ".5", want: 50},
+		{in: " 12.50 ", want: 1250},
+		{in: "92233720368547758.07", want: 9223372036854775807},
+		{in: "", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1.-5", wantErr: true},
+		{in: "1.+5", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "+-1", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "92233720368547758.08", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -13,35 +13,74 @@ import SelectedFlightSummary from "../../components/selected-flight-summary"
 type SortOption = "best" | "cheapest" | "fastest"
 
 // Interface for flight data from API
-interface ApiFlightDetail {
-  aircraft: string
-  arrival: string
-  arrival_date: string
-  arrival_time: string
-  departure: string
-  departure_date: string
-  departure_time: string
-  duration: number
+interface ApiAirportInfo {
+  code: string
+  name: string
+  city?: string
+  country?: string
+  time_zone?: string
+}
+
+interface ApiAirlineInfo {
+  code: string
+  name: string
+  logo_url?: string
+}
+
+interface ApiFlightSegment {
+  origin: string
+  destination: string
+  departure: string // RFC 3339, local time of the origin airport
+  arrival: string // RFC 3339, local time of the destination airport
   marketing_carrier: string
-  number: string
   operating_carrier: string
+  flight_number: string
+  aircraft: string
+  duration_minutes: number
+  origin_airport?: ApiAirportInfo
+  destination_airport?: ApiAirportInfo
+  marketing_airline?: ApiAirlineInfo
+  operating_airline?: ApiAirlineInfo
+  aircraft_type?: { code: string; name: string; manufacturer?: string }
+  red_eye?: boolean
 }
 
-interface ApiSegment {
-  flight: ApiFlightDetail[]
+interface ApiLayover {
+  airport: string
+  departure_airport: string
+  duration_minutes: number
+  short: boolean
+  overnight: boolean
+  airport_change: boolean
+  self_transfer: boolean
 }
 
-interface ApiTerms {
-  [key: string]: {
-    currency: string
-    price: number
-    unified_price: number
-  }
+interface ApiLeg {
+  origin: string
+  destination: string
+  departure: string
+  arrival: string
+  duration_minutes: number
+  stops: number
+  segments: ApiFlightSegment[]
+  layovers?: ApiLayover[]
+}
+
+interface ApiFare {
+  provider: string
+  gate: string
+  currency: string
+  price: number
+  unified_price: number
 }
 
-interface ApiProposal {
-  terms: ApiTerms
-  segment: ApiSegment[]
+interface ApiItinerary {
+  id: string
+  legs: ApiLeg[]
+  carriers: string[]
+  is_direct: boolean
+  duration_minutes: number
+  fares: ApiFare[]
 }
 
 // Helper function to create a default flight object when data is missing
@@ -69,22 +108,29 @@ function createDefaultFlightObject(index: number, price: number) {
   }
 }
 
-// Helper function to get airline name from carrier code
-function getAirlineName(carrierCode: string | undefined): string {
-  if (!carrierCode) return "Unknown Airline"
-
-  const airlineMap: {[key: string]: string} = {
-    "IX": "Air India Express",
-    "AI": "Air India",
-    "UK": "Vistara",
-    "6E": "IndiGo",
-    "SG": "SpiceJet",
-    "G8": "GoAir",
-    "I5": "AirAsia India",
-    "QP": "Akasa Air"
+// Airport label from the enriched airport details, falling back to the bare code
+function airportLabel(code: string | undefined, info: ApiAirportInfo | undefined): string {
+  if (!code) return "N/A"
+  return info?.city ? `${info.city} (${code})` : code
+}
+
+// Connection warnings of a leg shown on its flight card
+function legWarnings(leg: ApiLeg): string[] {
+  const warnings: string[] = []
+  for (const l of leg.layovers || []) {
+    if (l.airport_change) warnings.push(`Change airports ${l.airport} → ${l.departure_airport}`)
+    if (l.short) warnings.push(`Short connection (${l.duration_minutes}m) in ${l.airport}`)
+    if (l.self_transfer && !l.airport_change) warnings.push(`Self-transfer in ${l.airport}`)
+    if (l.overnight) warnings.push(`Overnight layover in ${l.airport}`)
   }
+  if (leg.segments.some((s) => s.red_eye)) warnings.push("Red-eye flight")
+  return warnings
+}
 
-  return airlineMap[carrierCode] || `${carrierCode} Airlines`
+// Take HH:MM from an RFC 3339 timestamp without shifting it to the browser's time zone
+function localClock(timestamp: string | undefined): string {
+  const match = timestamp?.match(/T(\d{2}:\d{2})/)
+  return match ? match[1] : "00:00"
 }
 
 // Add a small helper at top-level
@@ -98,43 +144,40 @@ function formatCurrency(value: number, currency: string) {
 }
 
 // Inside transformApiFlights
-const transformApiFlights = (apiFlights: ApiProposal[] | null): any[] => {
-  if (!apiFlights || apiFlights.length === 0) return []
+const transformApiFlights = (apiItineraries: ApiItinerary[] | null): any[] => {
+  if (!apiItineraries || apiItineraries.length === 0) return []
 
-  return apiFlights.map((proposal, index) => {
+  return apiItineraries.map((itinerary, index) => {
     try {
-      if (!proposal.segment || proposal.segment.length === 0 ||
-          !proposal.segment[0].flight || proposal.segment[0].flight.length === 0) {
+      const leg = itinerary.legs?.[0]
+      if (!leg || !leg.segments || leg.segments.length === 0) {
         return createDefaultFlightObject(index, 0)
       }
 
-      const segment = proposal.segment[0]
-      const flights = segment.flight
-      const totalDuration = flights.reduce((total, f) => total + (f.duration || 0), 0)
-      const firstFlight = flights[0]
-      const lastFlight = flights[flights.length - 1]
-      const airline = getAirlineName(firstFlight.marketing_carrier)
-      const stopCount = Math.max(0, flights.length - 1)
-
-      const termKey = Object.keys(proposal.terms)[0]
-      const term = termKey ? proposal.terms[termKey] : undefined
+      const totalDuration = leg.duration_minutes || 0
+      const first = leg.segments[0]
+      const last = leg.segments[leg.segments.length - 1]
+      const airline = first.marketing_airline?.name || first.marketing_carrier || "Unknown Airline"
+      const stopCount = leg.stops ?? Math.max(0, leg.segments.length - 1)
 
-      const originalPrice = term?.price ?? 0
-      const currency = term?.currency ?? 'USD' // default safe fallback
+      // fares are sorted cheapest first by the backend
+      const fare = itinerary.fares?.[0]
+      const originalPrice = fare?.price ?? 0
+      const currency = fare?.currency || 'USD' // default safe fallback
 
-      // Prefer `unified_price` for sorting across mixed currencies (if provided by API)
-      const sortValue = term?.unified_price ?? originalPrice
+      // Prefer `unified_price` for sorting across mixed currencies
+      const sortValue = fare?.unified_price ?? originalPrice
 
       return {
         id: index + 1,
         airline,
-        logo: "/placeholder.svg?height=40&width=40",
-        departureTime: firstFlight.departure_time || "00:00",
-        arrivalTime: lastFlight.arrival_time || "00:00",
+        logo: first.marketing_airline?.logo_url || "/placeholder.svg?height=40&width=40",
+        departureTime: localClock(leg.departure),
+        arrivalTime: localClock(leg.arrival),
         duration: `${Math.floor(totalDuration / 60)}h ${totalDuration % 60}m`,
         durationMinutes: totalDuration,
-        departureAirport: firstFlight.departure || "N/A",
-        arrivalAirport: lastFlight.arrival || "N/A",
+        departureAirport: airportLabel(leg.origin, first.origin_airport),
+        arrivalAirport: airportLabel(leg.destination, last.destination_airport),
 
         // Display the original currency
         price: formatCurrency(originalPrice, currency),
@@ -148,13 +191,14 @@ const transformApiFlights = (apiFlights: ApiProposal[] | null): any[] => {
 
         stops: stopCount === 0 ? "Nonstop" : `${stopCount} stop${stopCount > 1 ? 's' : ''}`,
         stopCount,
+        warnings: legWarnings(leg),
         amenities: [],
         checkedBag: sortValue > 100, // heuristic, unchanged
         handBaggage: true,
         rating: 3.5,
       }
     } catch (error) {
-      console.error(`Error transforming proposal at index ${index}:`, error)
+      console.error(`Error transforming itinerary at index ${index}:`, error)
       return createDefaultFlightObject(index, 0)
     }
   })
@@ -219,6 +263,11 @@ export default function FlightsPage() {
         qs.set('adults', adults)
         qs.set('tripType', tripType)
         if (ret) qs.set('return', ret)
+        // fares come back converted server-side when a display currency is chosen
+        const currency = params.get('currency')
+        if (currency) qs.set('currency', currency)
+        // results are paged server-side; fetch the largest page and paginate locally
+        qs.set('pageSize', '200')
 
         const response = await fetch(`${apiBase}/flights?${qs.toString()}`, {
           method: 'GET',
@@ -235,12 +284,12 @@ export default function FlightsPage() {
         const data = await response.json()
 
         // Check if data has the expected structure
-        if (!data || !data.proposals || !Array.isArray(data.proposals)) {
+        if (!data || !data.itineraries || !Array.isArray(data.itineraries)) {
           throw new Error('API response missing expected data structure')
         }
 
         // Transform API data to frontend format
-        const transformedFlights = transformApiFlights(data.proposals)
+        const transformedFlights = transformApiFlights(data.itineraries)
 
         if (transformedFlights.length > 0) {
           setFlightResults(transformedFlights)
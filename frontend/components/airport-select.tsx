@@ -3,10 +3,38 @@
 import React, { useEffect, useMemo, useRef, useState } from "react"
 
 export type AirportOption = {
+  // "city" options search every airport of a multi-airport city by its city code
+  type?: "airport" | "city"
   code: string
   name: string
   city?: string
   country?: string
+  airports?: AirportOption[]
+}
+
+function toOption(i: any): AirportOption {
+  return {
+    type: i.type === "city" ? "city" : "airport",
+    code: i.code,
+    name: i.name,
+    city: i.city,
+    country: i.country,
+    airports: Array.isArray(i.airports) ? i.airports.map(toOption) : undefined,
+  }
+}
+
+type Row = AirportOption & { nested?: boolean }
+
+// flatten lists a city followed by its airports so either can be picked
+function flatten(options: AirportOption[]): Row[] {
+  return options.flatMap((o) =>
+    o.type === "city" && o.airports ? [o, ...o.airports.map((a) => ({ ...a, nested: true }))] : [o]
+  )
+}
+
+function describe(o: AirportOption): string {
+  if (o.type === "city") return `${o.name} — all airports (${o.code})`
+  return `${o.city ? o.city + " — " : ""}${o.name} (${o.code})`
 }
 
 interface AirportSelectProps {
@@ -18,7 +46,7 @@ interface AirportSelectProps {
 
 export default function AirportSelect({ label, value, onChange, placeholder }: AirportSelectProps) {
   const [query, setQuery] = useState("")
-  const [options, setOptions] = useState<AirportOption[]>([])
+  const [options, setOptions] = useState<Row[]>([])
   const [open, setOpen] = useState(false)
   const [loading, setLoading] = useState(false)
   const controllerRef = useRef<AbortController | null>(null)
@@ -68,14 +96,7 @@ export default function AirportSelect({ label, value, onChange, placeholder }: A
         if (!res.ok) throw new Error(`HTTP ${res.status}`)
         const data = await res.json()
         const items = (data?.items || []) as any[]
-        setOptions(
-          items.map((i) => ({
-            code: i.code,
-            name: i.name,
-            city: i.city,
-            country: i.country,
-          }))
-        )
+        setOptions(flatten(items.map(toOption)))
       } catch (e) {
         if ((e as any).name !== "AbortError") {
           console.error("Autocomplete failed", e)
@@ -100,7 +121,7 @@ export default function AirportSelect({ label, value, onChange, placeholder }: A
           <input
             className="w-full border-0 bg-transparent p-0 text-sm font-medium text-slate-700 placeholder:text-slate-400 focus:outline-none"
             placeholder={placeholder || "Search airport or city"}
-            value={value ? `${value.city ? value.city + " — " : ""}${value.name} (${value.code})` : query}
+            value={value ? describe(value) : query}
             onChange={(e) => {
               onChange(null)
               setQuery(e.target.value)
@@ -118,20 +139,20 @@ export default function AirportSelect({ label, value, onChange, placeholder }: A
             {!loading && options.length > 0 && (
               <ul className="max-h-60 overflow-auto py-1">
                 {options.map((opt) => (
-                  <li key={opt.code + opt.name}>
+                  <li key={(opt.type || "airport") + opt.code}>
                     <button
                       type="button"
-                      className="flex w-full flex-col gap-0.5 px-4 py-2 text-left transition hover:bg-slate-50"
+                      className={`flex w-full flex-col gap-0.5 py-2 pr-4 text-left transition hover:bg-slate-50 ${
+                        opt.nested ? "pl-8" : "pl-4"
+                      }`}
                       onClick={() => {
-                        onChange(opt)
+                        const { nested, ...picked } = opt
+                        onChange(picked)
                         setQuery("")
                         setOpen(false)
                       }}
                     >
-                      <span className="text-sm font-semibold text-slate-900">
-                        {opt.city ? `${opt.city} — ` : ""}
-                        {opt.name} ({opt.code})
-                      </span>
+                      <span className="text-sm font-semibold text-slate-900">{describe(opt)}</span>
                       <span className="text-xs text-slate-500">{opt.country}</span>
                     </button>
                   </li>
@@ -15,6 +15,7 @@ interface FlightCardProps {
     arrivalAirport: string
     price: string
     stops: string
+    warnings?: string[]
     amenities: string[]
     checkedBag: boolean
     handBaggage: boolean
@@ -51,6 +52,9 @@ export default function FlightCard({ flight, handleSelectFlight }: FlightCardPro
             <div className="h-[1px] flex-1 bg-gray-300"></div>
           </div>
           <div className="text-center text-xs text-gray-500 mt-1">{flight.stops}</div>
+          {flight.warnings && flight.warnings.length > 0 && (
+            <div className="text-center text-xs text-amber-600 mt-1">{flight.warnings.join(" · ")}</div>
+          )}
         </div>
 
         <div className="text-center">
@@ -2,7 +2,7 @@
 const nextConfig = {
   reactStrictMode: true,
   images: {
-    domains: ['localhost'],
+    domains: ['localhost', 'pics.avs.io'],
   },
   // Allow cross-origin requests during development
   experimental: {